@@ -0,0 +1,133 @@
+// config/migrate.go
+package config
+
+import (
+	"log"
+	"time"
+
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+)
+
+// MigrateDatabase menjalankan AutoMigrate untuk semua model lalu memperbaiki
+// data lama agar sesuai dengan skema terbaru. Semua langkah aman dijalankan berulang kali.
+func MigrateDatabase() error {
+	// team_members memakai model kustom agar bisa menyimpan role anggota
+	if err := DB.SetupJoinTable(&models.Team{}, "Members", &models.TeamMember{}); err != nil {
+		return err
+	}
+	if err := DB.SetupJoinTable(&models.User{}, "Teams", &models.TeamMember{}); err != nil {
+		return err
+	}
+
+	err := DB.AutoMigrate(
+		&models.User{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.Todo{},
+		&models.Invitation{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.RecoveryCode{},
+		&models.MFAChallenge{},
+		&models.TeamJoinLink{},
+		&models.Comment{},
+		&models.ChecklistItem{},
+		&models.Label{},
+		&models.TeamStatus{},
+		&models.StatusTransition{},
+		&models.TodoActivity{},
+		&models.TodoReminder{},
+		&models.Notification{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// AutoMigrate tidak selalu mendeteksi perubahan nilai enum, jadi ubah kolomnya secara eksplisit
+	if err := DB.Migrator().AlterColumn(&models.Invitation{}, "Status"); err != nil {
+		return err
+	}
+
+	// Anggota lama mendapat role default 'editor'; pastikan pemilik tim memiliki role 'owner'.
+	result := DB.Exec(`UPDATE team_members
+		JOIN teams ON teams.id = team_members.team_id
+		SET team_members.role = ?
+		WHERE team_members.user_id = teams.owner_id AND team_members.role <> ?`, models.RoleOwner, models.RoleOwner)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned owner role to %d existing team owner(s)", result.RowsAffected)
+	}
+
+	// Undangan lama belum menyimpan email; isi dari data user yang diundang.
+	if err := DB.Exec(`UPDATE invitations
+		JOIN users ON users.id = invitations.user_id
+		SET invitations.email = users.email
+		WHERE invitations.email IS NULL OR invitations.email = ''`).Error; err != nil {
+		return err
+	}
+
+	// Undangan lama belum punya masa berlaku; beri masa tenggang penuh mulai sekarang.
+	if err := DB.Model(&models.Invitation{}).
+		Where("status = ? AND expires_at IS NULL", models.InvitationPending).
+		Update("expires_at", time.Now().Add(utils.InvitationTTL)).Error; err != nil {
+		return err
+	}
+
+	// Tim lama belum memiliki status alur kerja; buat status bawaan yang meniru enum lama.
+	var teamIDs []uint
+	if err := DB.Model(&models.Team{}).
+		Where("id NOT IN (?)", DB.Model(&models.TeamStatus{}).Select("team_id")).
+		Pluck("id", &teamIDs).Error; err != nil {
+		return err
+	}
+	for _, teamID := range teamIDs {
+		if err := models.CreateDefaultStatuses(DB, teamID); err != nil {
+			return err
+		}
+	}
+	if len(teamIDs) > 0 {
+		log.Printf("Created default workflow statuses for %d existing team(s)", len(teamIDs))
+	}
+
+	// Todo lama dipetakan ke status bawaan dengan nama yang sama (pending -> Pending, dst.)
+	if err := DB.Exec(`UPDATE todos
+		JOIN team_statuses ON team_statuses.team_id = todos.team_id AND LOWER(team_statuses.name) = todos.status
+		SET todos.status_id = team_statuses.id
+		WHERE todos.status_id IS NULL`).Error; err != nil {
+		return err
+	}
+
+	// Todo lama belum punya rank papan; isi per kolom dengan yang terbaru di paling atas.
+	var columns []struct {
+		TeamID   uint
+		StatusID *uint
+	}
+	if err := DB.Model(&models.Todo{}).
+		Where("`rank` = '' OR `rank` IS NULL").
+		Distinct("team_id", "status_id").
+		Scan(&columns).Error; err != nil {
+		return err
+	}
+	for _, column := range columns {
+		var ids []uint
+		query := DB.Model(&models.Todo{}).Where("team_id = ?", column.TeamID)
+		if column.StatusID == nil {
+			query = query.Where("status_id IS NULL")
+		} else {
+			query = query.Where("status_id = ?", *column.StatusID)
+		}
+		if err := query.Order("created_at desc, id desc").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for i, rank := range utils.RankSequence(len(ids)) {
+			if err := DB.Model(&models.Todo{}).Where("id = ?", ids[i]).UpdateColumn("rank", rank).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
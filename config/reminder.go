@@ -0,0 +1,20 @@
+// config/reminder.go
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultReminderLeadHours dipakai jika REMINDER_LEAD_HOURS tidak diisi atau tidak valid.
+const defaultReminderLeadHours = 24
+
+// ReminderLeadTime mengembalikan seberapa lama sebelum tenggat pengingat "segera jatuh tempo" dikirim.
+func ReminderLeadTime() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("REMINDER_LEAD_HOURS"))
+	if err != nil || hours < 1 {
+		hours = defaultReminderLeadHours
+	}
+	return time.Duration(hours) * time.Hour
+}
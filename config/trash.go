@@ -0,0 +1,20 @@
+// config/trash.go
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultTrashRetentionDays dipakai jika TRASH_RETENTION_DAYS tidak diisi atau tidak valid.
+const defaultTrashRetentionDays = 30
+
+// TrashRetention mengembalikan lama item disimpan di tempat sampah sebelum dihapus permanen.
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days < 1 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
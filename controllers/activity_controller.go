@@ -0,0 +1,148 @@
+// controllers/activity_controller.go
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+// todoFieldValues mengambil nilai field todo yang dicatat di riwayat, dalam bentuk string.
+func todoFieldValues(todo models.Todo) map[string]*string {
+	str := func(s string) *string { return &s }
+	values := map[string]*string{
+		"title":                   str(todo.Title),
+		"description":             str(todo.Description),
+		"status":                  str(string(todo.Status)),
+		"urgency":                 str(string(todo.Urgency)),
+		"auto_complete_checklist": str(strconv.FormatBool(todo.AutoCompleteChecklist)),
+		"recurrence":              str(todo.Recurrence),
+		"status_id":               nil,
+		"due_date":                nil,
+	}
+	if todo.StatusID != nil {
+		values["status_id"] = str(strconv.FormatUint(uint64(*todo.StatusID), 10))
+	}
+	if todo.DueDate != nil {
+		values["due_date"] = str(todo.DueDate.Format(time.RFC3339))
+	}
+	return values
+}
+
+// todoActivityFields menentukan urutan field saat perubahan dicatat.
+var todoActivityFields = []string{"title", "description", "status_id", "status", "urgency", "due_date", "recurrence", "auto_complete_checklist"}
+
+// recordTodoChanges mencatat setiap field yang berbeda antara oldValues (diambil dengan
+// todoFieldValues sebelum perubahan) dan todo setelah perubahan.
+func recordTodoChanges(tx *gorm.DB, oldValues map[string]*string, after models.Todo, actorID uint) error {
+	newValues := todoFieldValues(after)
+
+	var activities []models.TodoActivity
+	for _, field := range todoActivityFields {
+		oldValue, newValue := oldValues[field], newValues[field]
+		if (oldValue == nil && newValue == nil) || (oldValue != nil && newValue != nil && *oldValue == *newValue) {
+			continue
+		}
+		activities = append(activities, models.TodoActivity{
+			TeamID:   after.TeamID,
+			TodoID:   after.ID,
+			ActorID:  actorID,
+			Action:   models.ActivityUpdated,
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	if len(activities) == 0 {
+		return nil
+	}
+	return tx.Create(&activities).Error
+}
+
+// recordTodoActivity mencatat satu aksi pada todo (dibuat, dihapus, ditugaskan, dll.).
+func recordTodoActivity(tx *gorm.DB, todo models.Todo, actorID uint, action, field string, oldValue, newValue *string) error {
+	return tx.Create(&models.TodoActivity{
+		TeamID:   todo.TeamID,
+		TodoID:   todo.ID,
+		ActorID:  actorID,
+		Action:   action,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}).Error
+}
+
+// listActivities mengambil satu halaman riwayat, terbaru lebih dulu.
+// Halaman berikutnya diambil dengan ?before_id=<next_before_id>.
+func listActivities(c *gin.Context, query *gorm.DB) {
+	limit := defaultActivityPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		if n > maxActivityPageSize {
+			n = maxActivityPageSize
+		}
+		limit = n
+	}
+	if raw := c.Query("before_id"); raw != "" {
+		beforeID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before_id"})
+			return
+		}
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var activities []models.TodoActivity
+	if err := query.Preload("Actor").Order("id desc").Limit(limit + 1).Find(&activities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activity"})
+		return
+	}
+
+	var nextBeforeID *uint
+	if len(activities) > limit {
+		activities = activities[:limit]
+		nextBeforeID = &activities[limit-1].ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": activities, "next_before_id": nextBeforeID})
+}
+
+// GetTodoHistory mengambil riwayat perubahan sebuah todo.
+// Rute: GET /api/teams/:teamId/todos/:todoId/history
+func GetTodoHistory(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	listActivities(c, config.DB.Where("todo_id = ?", todo.ID))
+}
+
+// GetTeamActivity mengambil riwayat perubahan semua todo di tim, termasuk todo yang
+// sudah dihapus. Filter opsional ?actor=<userId> dan ?todo_id=<todoId>.
+// Rute: GET /api/teams/:teamId/activity
+func GetTeamActivity(c *gin.Context) {
+	query := config.DB.Where("team_id = ?", c.Param("teamId"))
+	if actor := c.Query("actor"); actor != "" {
+		query = query.Where("actor_id = ?", actor)
+	}
+	if todoID := c.Query("todo_id"); todoID != "" {
+		query = query.Where("todo_id = ?", todoID)
+	}
+
+	listActivities(c, query)
+}
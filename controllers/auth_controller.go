@@ -4,28 +4,34 @@ package controllers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"notedteam.backend/config"
 	"notedteam.backend/models"
 	"notedteam.backend/utils"
+	"notedteam.backend/ws"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // RegisterInput mendefinisikan data yang dibutuhkan untuk registrasi
 type RegisterInput struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Name        string `json:"name" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,min=6"`
+	InviteToken string `json:"invite_token"` // Opsional, dari link undangan tim via email
 }
 
 // LoginInput mendefinisikan data yang dibutuhkan untuk login
 type LoginInput struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name"` // Opsional, contoh: "Pixel 8"
 }
 
 func generateSecureToken(length int) (string, error) {
@@ -52,6 +58,13 @@ func Register(c *gin.Context) {
 
 	// user := models.User{Name: input.Name, Email: input.Email, Password: string(hashedPassword)}
 
+	// Pendaftaran lewat link undangan: email sudah terbukti milik user,
+	// jadi akun langsung terverifikasi dan undangannya langsung ditautkan.
+	if input.InviteToken != "" {
+		registerWithInvitation(c, input, string(hashedPassword))
+		return
+	}
+
 	token, err := generateSecureToken(32)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate verification token"})
@@ -77,6 +90,39 @@ func Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Registration successful. Please check your email to verify your account."})
 }
 
+// registerWithInvitation membuat akun terverifikasi untuk pemilik token undangan via email.
+func registerWithInvitation(c *gin.Context, input RegisterInput, hashedPassword string) {
+	invitation, err := findInvitationByToken(input.InviteToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation token"})
+		return
+	}
+	if !strings.EqualFold(invitation.Email, input.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please register with the email address the invitation was sent to"})
+		return
+	}
+
+	user := models.User{
+		Name:       input.Name,
+		Email:      input.Email,
+		Password:   hashedPassword,
+		IsVerified: true,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return attachPendingInvitations(tx, user)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Registration successful. You can now log in and accept your team invitation."})
+}
+
 func Login(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -97,17 +143,34 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate token JWT
-	token, err := utils.GenerateToken(user.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
-		return
-	}
 	if !user.IsVerified {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account not verified. Please check your email."})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+
+	// Jika 2FA aktif, jangan terbitkan JWT dulu. Kirim token tantangan
+	// yang harus ditukar bersama kode 2FA di /auth/login/mfa.
+	if user.TOTPEnabled {
+		mfaToken, err := startMFAChallenge(user.ID, input.DeviceName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(utils.MFATokenTTL.Seconds()),
+		})
+		return
+	}
+
+	// Buat sesi baru beserta access token & refresh token
+	tokens, err := startSession(user, sessionMetaFromRequest(c, input.DeviceName))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
 }
 func VerifyEmail(c *gin.Context) {
 	token := c.Query("token")
@@ -127,6 +190,11 @@ func VerifyEmail(c *gin.Context) {
 	user.VerificationToken = "" // Hapus token setelah digunakan
 	config.DB.Save(&user)
 
+	// Email sudah terbukti: tautkan undangan tim yang dikirim ke email ini sebelum user mendaftar
+	if err := attachPendingInvitations(config.DB, user); err != nil {
+		log.Printf("Failed to attach pending invitations for user %d: %v", user.ID, err)
+	}
+
 	// Tampilkan halaman sukses sederhana
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<h1>Email Verified!</h1><p>Your account has been successfully verified. You can now close this window and log in to the application.</p>"))
 }
@@ -210,7 +278,26 @@ func ResetPassword(c *gin.Context) {
 	user.Password = string(hashedPassword)
 	user.PasswordResetToken = ""
 	user.PasswordResetTokenExp = nil // Set ke null
-	config.DB.Save(&user)
+
+	// Password berubah: paksa semua perangkat untuk login ulang. Pencabutan sesi
+	// satu transaksi dengan password baru agar tidak ada refresh token yang lolos.
+	var sessionIDs []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Pluck("id", &sessionIDs).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID)
+	})
+	if err != nil {
+		c.Data(http.StatusInternalServerError, "text/html; charset=utf-8", []byte("<h1>Error</h1><p>Failed to reset password. Please try again.</p>"))
+		return
+	}
+	for _, sessionID := range sessionIDs {
+		ws.AppHub.DisconnectSession(sessionID)
+	}
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<h1>Success!</h1><p>Your password has been reset. You can now close this window and log in with your new password.</p>"))
 }
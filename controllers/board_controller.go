@@ -0,0 +1,243 @@
+// controllers/board_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
+)
+
+// maxRankLength adalah batas panjang rank sebelum satu kolom diseimbangkan ulang.
+const maxRankLength = 32
+
+var errInvalidNeighbours = errors.New("invalid neighbour cards")
+
+// MoveTodoInput mendefinisikan posisi baru sebuah kartu di papan.
+// after_id adalah kartu tepat di atasnya, before_id kartu tepat di bawahnya;
+// kosongkan keduanya untuk kolom kosong, atau salah satunya untuk ujung kolom.
+type MoveTodoInput struct {
+	StatusID *uint `json:"status_id"` // Opsional, default kolom saat ini
+	AfterID  *uint `json:"after_id"`
+	BeforeID *uint `json:"before_id"`
+}
+
+// columnQuery membatasi query ke todo dalam satu kolom (tim + status).
+func columnQuery(db *gorm.DB, teamID uint, statusID *uint) *gorm.DB {
+	query := db.Model(&models.Todo{}).Where("team_id = ?", teamID)
+	if statusID == nil {
+		return query.Where("status_id IS NULL")
+	}
+	return query.Where("status_id = ?", *statusID)
+}
+
+// firstRankInColumn mengambil rank terkecil yang terisi di kolom, atau "" jika kolom kosong.
+func firstRankInColumn(tx *gorm.DB, teamID uint, statusID *uint) (string, error) {
+	var first string
+	err := columnQuery(tx, teamID, statusID).Where("`rank` <> ''").Order("`rank` asc").Limit(1).Pluck("rank", &first).Error
+	return first, err
+}
+
+// rankAtTopOfColumn menghitung rank untuk kartu baru di paling atas kolom. Jika rank itu
+// terlalu panjang atau bertabrakan, kolom diseimbangkan ulang dulu dan rebalanced bernilai true.
+func rankAtTopOfColumn(tx *gorm.DB, teamID uint, statusID *uint) (rank string, rebalanced bool, err error) {
+	first, err := firstRankInColumn(tx, teamID, statusID)
+	if err != nil {
+		return "", false, err
+	}
+	rank = utils.RankBetween("", first)
+	if len(rank) <= maxRankLength && (first == "" || rank < first) {
+		return rank, false, nil
+	}
+
+	if err := rebalanceColumn(tx, teamID, statusID); err != nil {
+		return "", false, err
+	}
+	if first, err = firstRankInColumn(tx, teamID, statusID); err != nil {
+		return "", false, err
+	}
+	return utils.RankBetween("", first), true, nil
+}
+
+// rebalanceColumn memberi rank baru yang berjarak rata ke semua kartu di kolom,
+// tanpa mengubah urutannya. Dipakai saat rank menjadi terlalu panjang atau bertabrakan.
+func rebalanceColumn(tx *gorm.DB, teamID uint, statusID *uint) error {
+	var ids []uint
+	if err := columnQuery(tx, teamID, statusID).Order("`rank` asc, created_at desc, id desc").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for i, rank := range utils.RankSequence(len(ids)) {
+		if err := tx.Model(&models.Todo{}).Where("id = ?", ids[i]).UpdateColumn("rank", rank).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnRank adalah rank satu kartu, dikirim ke klien setelah kolomnya diseimbangkan ulang.
+type columnRank struct {
+	ID   uint   `json:"id"`
+	Rank string `json:"rank"`
+}
+
+// columnRanks mengambil rank semua kartu di kolom agar klien tidak perlu mengambil ulang.
+func columnRanks(teamID uint, statusID *uint) []columnRank {
+	var ranks []columnRank
+	columnQuery(config.DB, teamID, statusID).Select("id, `rank`").Order("`rank` asc").Scan(&ranks)
+	return ranks
+}
+
+// broadcastColumnRebalanced mengirim event column_rebalanced berisi rank baru semua kartu
+// di kolom. Dipakai saat kartu baru memicu penyeimbangan ulang kolom.
+func broadcastColumnRebalanced(teamID uint, statusID *uint) {
+	ws.AppHub.BroadcastToTeam(teamID, "column_rebalanced", gin.H{
+		"status_id":    statusID,
+		"column_ranks": columnRanks(teamID, statusID),
+	})
+}
+
+// neighbourRanks mengambil rank kartu after/before yang harus berada di kolom yang sama.
+func neighbourRanks(tx *gorm.DB, teamID uint, statusID *uint, todoID uint, afterID, beforeID *uint) (string, string, bool) {
+	var after, before string
+	for _, n := range []struct {
+		id   *uint
+		rank *string
+	}{{afterID, &after}, {beforeID, &before}} {
+		if n.id == nil {
+			continue
+		}
+		var neighbour models.Todo
+		if *n.id == todoID || columnQuery(tx, teamID, statusID).Where("id = ?", *n.id).First(&neighbour).Error != nil {
+			return "", "", false
+		}
+		*n.rank = neighbour.Rank
+	}
+	return after, before, true
+}
+
+// MoveTodo memindahkan kartu ke posisi lain di kolom yang sama atau ke kolom status lain.
+// Rute: POST /api/teams/:teamId/todos/:todoId/move
+func MoveTodo(c *gin.Context) {
+	var input MoveTodoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+	var fromStatusID *uint
+	if todo.StatusID != nil {
+		id := *todo.StatusID
+		fromStatusID = &id
+	}
+
+	// Tentukan kolom tujuan dan validasi perpindahan status
+	targetStatusID := todo.StatusID
+	legacy := todo.Status
+	if input.StatusID != nil {
+		status, err := resolveTeamStatus(todo.TeamID, input.StatusID, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !canTransition(todo.TeamID, todo.StatusID, status.ID) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errTransitionNotAllowed.Error()})
+			return
+		}
+		targetStatusID = &status.ID
+		legacy = legacyStatusOf(status)
+	}
+
+	userID, _ := c.Get("user_id")
+	oldValues := todoFieldValues(todo)
+	rebalanced := false
+	var nextOccurrence *spawnedOccurrence
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		after, before, ok := neighbourRanks(tx, todo.TeamID, targetStatusID, todo.ID, input.AfterID, input.BeforeID)
+		if !ok {
+			return errInvalidNeighbours
+		}
+
+		// Rank lama bisa kosong/bertabrakan; seimbangkan ulang kolom lalu baca lagi
+		if (input.AfterID != nil && after == "") || (input.BeforeID != nil && before == "") ||
+			(after != "" && before != "" && after >= before) {
+			if err := rebalanceColumn(tx, todo.TeamID, targetStatusID); err != nil {
+				return err
+			}
+			rebalanced = true
+			after, before, _ = neighbourRanks(tx, todo.TeamID, targetStatusID, todo.ID, input.AfterID, input.BeforeID)
+			if after != "" && before != "" && after >= before {
+				return errInvalidNeighbours
+			}
+		}
+
+		rank := utils.RankBetween(after, before)
+		if err := tx.Model(&todo).Updates(map[string]interface{}{
+			"rank":      rank,
+			"status_id": targetStatusID,
+			"status":    legacy,
+			"editor_id": userID,
+			"version":   gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+
+		var updated models.Todo
+		if err := tx.First(&updated, todo.ID).Error; err != nil {
+			return err
+		}
+		if err := recordTodoChanges(tx, oldValues, updated, userID.(uint)); err != nil {
+			return err
+		}
+		if todo.Status != models.StatusCompleted && updated.Status == models.StatusCompleted {
+			next, err := spawnNextOccurrence(tx, updated, userID.(uint))
+			if err != nil {
+				return err
+			}
+			nextOccurrence = next
+		}
+
+		if len(rank) > maxRankLength {
+			rebalanced = true
+			return rebalanceColumn(tx, todo.TeamID, targetStatusID)
+		}
+		return nil
+	})
+	if errors.Is(err, errInvalidNeighbours) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after_id and before_id must be other cards in the target column, with after_id above before_id"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move todo"})
+		return
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	event := gin.H{
+		"todo_id":        todo.ID,
+		"from_status_id": fromStatusID,
+		"status_id":      todo.StatusID,
+		"rank":           todo.Rank,
+		"todo":           todo,
+	}
+	if rebalanced {
+		event["column_ranks"] = columnRanks(todo.TeamID, todo.StatusID)
+	}
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_moved", event)
+	broadcastNextOccurrence(nextOccurrence)
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{"data": todo})
+}
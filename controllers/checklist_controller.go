@@ -0,0 +1,300 @@
+// controllers/checklist_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// CreateChecklistItemInput mendefinisikan data untuk menambah item checklist.
+type CreateChecklistItemInput struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// UpdateChecklistItemInput memakai pointer agar field yang tidak dikirim tidak ikut diubah.
+type UpdateChecklistItemInput struct {
+	Title *string `json:"title"`
+	Done  *bool   `json:"done"`
+}
+
+// ReorderChecklistInput berisi semua ID item checklist dalam urutan yang baru.
+type ReorderChecklistInput struct {
+	ItemIDs []uint `json:"item_ids" binding:"required"`
+}
+
+// attachChecklistProgress mengisi jumlah item selesai/total untuk setiap todo dengan satu query.
+func attachChecklistProgress(todos []models.Todo) {
+	if len(todos) == 0 {
+		return
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	var rows []struct {
+		TodoID uint
+		Done   int
+		Total  int
+	}
+	config.DB.Model(&models.ChecklistItem{}).
+		Select("todo_id, SUM(CASE WHEN done THEN 1 ELSE 0 END) AS done, COUNT(*) AS total").
+		Where("todo_id IN ?", ids).
+		Group("todo_id").
+		Scan(&rows)
+
+	progress := make(map[uint]models.ChecklistProgress, len(rows))
+	for _, row := range rows {
+		progress[row.TodoID] = models.ChecklistProgress{Done: row.Done, Total: row.Total}
+	}
+	for i := range todos {
+		todos[i].ChecklistProgress = progress[todos[i].ID]
+	}
+}
+
+// attachTodoChecklistProgress sama seperti attachChecklistProgress untuk satu todo.
+func attachTodoChecklistProgress(todo *models.Todo) {
+	todos := []models.Todo{*todo}
+	attachChecklistProgress(todos)
+	todo.ChecklistProgress = todos[0].ChecklistProgress
+}
+
+// applyChecklistAutoComplete memindahkan todo ke status selesai pertama milik tim jika
+// fitur auto-complete aktif, semua item checklist-nya sudah selesai, dan alur kerja
+// tim mengizinkan perpindahan tersebut.
+func applyChecklistAutoComplete(todo models.Todo, actorID uint) {
+	if !todo.AutoCompleteChecklist || todo.Status == models.StatusCompleted {
+		return
+	}
+
+	var total, remaining int64
+	config.DB.Model(&models.ChecklistItem{}).Where("todo_id = ?", todo.ID).Count(&total)
+	config.DB.Model(&models.ChecklistItem{}).Where("todo_id = ? AND done = ?", todo.ID, false).Count(&remaining)
+	if total == 0 || remaining > 0 {
+		return
+	}
+
+	completed := models.StatusCompleted
+	status, err := resolveTeamStatus(todo.TeamID, nil, &completed)
+	if err != nil || !canTransition(todo.TeamID, todo.StatusID, status.ID) {
+		return
+	}
+
+	oldValues := todoFieldValues(todo)
+	var nextOccurrence *spawnedOccurrence
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&todo).Updates(map[string]interface{}{
+			"status":    models.StatusCompleted,
+			"status_id": status.ID,
+			"editor_id": actorID,
+			"version":   gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		var updated models.Todo
+		if err := tx.First(&updated, todo.ID).Error; err != nil {
+			return err
+		}
+		if err := recordTodoChanges(tx, oldValues, updated, actorID); err != nil {
+			return err
+		}
+		next, err := spawnNextOccurrence(tx, updated, actorID)
+		nextOccurrence = next
+		return err
+	})
+	if err != nil {
+		return
+	}
+	preloadTodo(config.DB).First(&todo, todo.ID)
+	attachTodoChecklistProgress(&todo)
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_updated", todo)
+	broadcastNextOccurrence(nextOccurrence)
+}
+
+// findChecklistItem mencari item :itemId milik todo yang diberikan.
+func findChecklistItem(c *gin.Context, todo models.Todo) (models.ChecklistItem, bool) {
+	var item models.ChecklistItem
+	if err := config.DB.Where("id = ? AND todo_id = ?", c.Param("itemId"), todo.ID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return item, false
+	}
+	return item, true
+}
+
+// GetChecklist mengambil item checklist sebuah todo sesuai urutannya.
+// Rute: GET /api/teams/:teamId/todos/:todoId/checklist
+func GetChecklist(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := config.DB.Where("todo_id = ?", todo.ID).Order("position asc, id asc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch checklist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// CreateChecklistItem menambahkan item baru di akhir checklist.
+// Rute: POST /api/teams/:teamId/todos/:todoId/checklist
+func CreateChecklistItem(c *gin.Context) {
+	var input CreateChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	var maxPosition *int
+	config.DB.Model(&models.ChecklistItem{}).Where("todo_id = ?", todo.ID).Select("MAX(position)").Scan(&maxPosition)
+	position := 0
+	if maxPosition != nil {
+		position = *maxPosition + 1
+	}
+
+	item := models.ChecklistItem{TodoID: todo.ID, Title: input.Title, Position: position}
+	if err := config.DB.Create(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create checklist item"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "checklist_item_created", item)
+
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// UpdateChecklistItem mengubah judul dan/atau menandai item selesai.
+// Rute: PATCH /api/teams/:teamId/todos/:todoId/checklist/:itemId
+func UpdateChecklistItem(c *gin.Context) {
+	var input UpdateChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+	item, ok := findChecklistItem(c, todo)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Title != nil {
+		updates["title"] = *input.Title
+	}
+	if input.Done != nil {
+		updates["done"] = *input.Done
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&item).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+			return
+		}
+		config.DB.First(&item, item.ID)
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "checklist_item_updated", item)
+
+	if input.Done != nil && *input.Done {
+		userID, _ := c.Get("user_id")
+		applyChecklistAutoComplete(todo, userID.(uint))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// ReorderChecklist menyimpan urutan baru item checklist.
+// item_ids harus berisi semua item milik todo tersebut, tepat satu kali.
+// Rute: PUT /api/teams/:teamId/todos/:todoId/checklist/order
+func ReorderChecklist(c *gin.Context) {
+	var input ReorderChecklistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	var existingIDs []uint
+	config.DB.Model(&models.ChecklistItem{}).Where("todo_id = ?", todo.ID).Pluck("id", &existingIDs)
+	if len(uniqueIDs(input.ItemIDs)) != len(input.ItemIDs) || len(input.ItemIDs) != len(existingIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "item_ids must contain every checklist item of this todo exactly once"})
+		return
+	}
+	existing := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+	for _, id := range input.ItemIDs {
+		if !existing[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item_ids must contain every checklist item of this todo exactly once"})
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range input.ItemIDs {
+			if err := tx.Model(&models.ChecklistItem{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder checklist"})
+		return
+	}
+
+	var items []models.ChecklistItem
+	config.DB.Where("todo_id = ?", todo.ID).Order("position asc").Find(&items)
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "checklist_reordered", gin.H{"todo_id": todo.ID, "items": items})
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// DeleteChecklistItem menghapus sebuah item checklist.
+// Rute: DELETE /api/teams/:teamId/todos/:todoId/checklist/:itemId
+func DeleteChecklistItem(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+	item, ok := findChecklistItem(c, todo)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete checklist item"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "checklist_item_deleted", gin.H{"id": item.ID, "todo_id": todo.ID})
+
+	// Menghapus satu-satunya item yang belum selesai juga bisa membuat checklist lengkap
+	userID, _ := c.Get("user_id")
+	applyChecklistAutoComplete(todo, userID.(uint))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item deleted successfully"})
+}
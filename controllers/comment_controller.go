@@ -0,0 +1,176 @@
+// controllers/comment_controller.go
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// CreateCommentInput mendefinisikan data untuk menulis komentar atau balasan.
+type CreateCommentInput struct {
+	Body     string `json:"body" binding:"required,max=10000"` // Markdown
+	ParentID *uint  `json:"parent_id"`                         // Isi untuk membalas komentar lain
+}
+
+// UpdateCommentInput mendefinisikan data untuk mengedit komentar.
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// findAuthoredComment mencari komentar :commentId pada todo :todoId dan memastikan
+// user yang sedang login adalah penulisnya.
+func findAuthoredComment(c *gin.Context) (models.Comment, bool) {
+	var comment models.Comment
+	if err := config.DB.
+		Where("id = ? AND todo_id = ? AND team_id = ?", c.Param("commentId"), c.Param("todoId"), c.Param("teamId")).
+		First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return comment, false
+	}
+
+	userID, _ := c.Get("user_id")
+	if comment.AuthorID != userID.(uint) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can modify this comment"})
+		return comment, false
+	}
+	return comment, true
+}
+
+// GetTodoComments mengambil semua komentar sebuah todo, urut dari yang terlama.
+// Thread dibentuk klien berdasarkan parent_id.
+// Rute: GET /api/teams/:teamId/todos/:todoId/comments
+func GetTodoComments(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	var comments []models.Comment
+	if err := config.DB.Preload("Author").Where("todo_id = ?", todo.ID).Order("created_at asc").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comments})
+}
+
+// CreateComment menambahkan komentar (atau balasan) ke sebuah todo.
+// Rute: POST /api/teams/:teamId/todos/:todoId/comments
+func CreateComment(c *gin.Context) {
+	var input CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	// Balasan hanya boleh ditujukan ke komentar pada todo yang sama
+	if input.ParentID != nil {
+		var parentCount int64
+		config.DB.Model(&models.Comment{}).Where("id = ? AND todo_id = ?", *input.ParentID, todo.ID).Count(&parentCount)
+		if parentCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment not found on this todo"})
+			return
+		}
+	}
+
+	authorID, _ := c.Get("user_id")
+	comment := models.Comment{
+		TodoID:   todo.ID,
+		TeamID:   todo.TeamID,
+		AuthorID: authorID.(uint),
+		ParentID: input.ParentID,
+		Body:     input.Body,
+	}
+	if err := config.DB.Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+	config.DB.Preload("Author").First(&comment, comment.ID)
+
+	ws.AppHub.BroadcastToTeam(comment.TeamID, "comment_created", comment)
+	notifyComment(comment, todo, nil)
+
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// UpdateComment mengedit isi komentar. Hanya penulis yang boleh mengedit.
+// Rute: PUT /api/teams/:teamId/todos/:todoId/comments/:commentId
+func UpdateComment(c *gin.Context) {
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, ok := findAuthoredComment(c)
+	if !ok {
+		return
+	}
+	previousBody := comment.Body
+
+	if err := config.DB.Model(&comment).Updates(map[string]interface{}{
+		"body":      input.Body,
+		"edited_at": time.Now(),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+	config.DB.Preload("Author").First(&comment, comment.ID)
+
+	ws.AppHub.BroadcastToTeam(comment.TeamID, "comment_updated", comment)
+
+	var todo models.Todo
+	if config.DB.First(&todo, comment.TodoID).Error == nil {
+		notifyComment(comment, todo, &previousBody)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// DeleteComment menghapus komentar beserta seluruh balasannya. Hanya penulis yang boleh menghapus.
+// Rute: DELETE /api/teams/:teamId/todos/:todoId/comments/:commentId
+func DeleteComment(c *gin.Context) {
+	comment, ok := findAuthoredComment(c)
+	if !ok {
+		return
+	}
+
+	var deletedIDs []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kumpulkan semua keturunan komentar, level demi level
+		deletedIDs = []uint{comment.ID}
+		parents := []uint{comment.ID}
+		for len(parents) > 0 {
+			var children []uint
+			if err := tx.Model(&models.Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+				return err
+			}
+			deletedIDs = append(deletedIDs, children...)
+			parents = children
+		}
+		return tx.Where("id IN ?", deletedIDs).Delete(&models.Comment{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(comment.TeamID, "comment_deleted", gin.H{
+		"id":          comment.ID,
+		"todo_id":     comment.TodoID,
+		"deleted_ids": deletedIDs,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
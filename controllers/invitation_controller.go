@@ -2,13 +2,18 @@
 package controllers
 
 import (
+	"errors"
+	"html"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"notedteam.backend/config"
 	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
 )
 
 // GetMyInvitations mengambil semua undangan yang tertunda untuk pengguna yang login.
@@ -17,7 +22,10 @@ func GetMyInvitations(c *gin.Context) {
 	var invitations []models.Invitation
 
 	// Preload("Team") untuk menyertakan informasi tim dalam respons
-	config.DB.Preload("Team").Where("user_id = ? AND status = ?", userID, models.InvitationPending).Find(&invitations)
+	config.DB.Preload("Team").
+		Joins("JOIN teams ON teams.id = invitations.team_id AND teams.deleted_at IS NULL").
+		Where("invitations.user_id = ? AND invitations.status = ? AND (invitations.expires_at IS NULL OR invitations.expires_at > ?)", userID, models.InvitationPending, time.Now()).
+		Find(&invitations)
 
 	c.JSON(http.StatusOK, gin.H{"data": invitations})
 }
@@ -41,6 +49,20 @@ func RespondToInvitation(c *gin.Context) {
 		return
 	}
 
+	// Notifikasi undangan ini tidak perlu ditindaklanjuti lagi
+	config.DB.Model(&models.Notification{}).Where("user_id = ? AND invitation_id = ?", userID, invitation.ID).Update("is_read", true)
+
+	// Hanya undangan yang masih tertunda dan belum kedaluwarsa yang bisa direspons
+	if invitation.Status != models.InvitationPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This invitation is no longer pending"})
+		return
+	}
+	if invitation.IsExpired() {
+		config.DB.Model(&invitation).Update("status", models.InvitationExpired)
+		c.JSON(http.StatusGone, gin.H{"error": "This invitation has expired"})
+		return
+	}
+
 	if input.Accept {
 		// --- TERIMA UNDANGAN ---
 		// 1. Ambil objek user dari context dengan aman
@@ -57,20 +79,16 @@ func RespondToInvitation(c *gin.Context) {
 			return
 		}
 
-		// 3. Jalankan Transaksi
-		err := config.DB.Transaction(func(tx *gorm.DB) error {
-			// Gunakan pointer ke objek user yang sudah di-assert (&user)
-			if err := tx.Model(&models.Team{ID: invitation.TeamID}).Association("Members").Append(&user); err != nil {
-				return err
-			}
-
+		// 3. Jalankan Transaksi. Anggota yang bergabung lewat undangan mendapat role editor.
+		err := joinTeam(invitation.TeamID, user.ID, models.RoleEditor, func(tx *gorm.DB) error {
 			invitation.Status = models.InvitationAccepted
-			if err := tx.Save(&invitation).Error; err != nil {
-				return err
-			}
-			return nil
+			return tx.Save(&invitation).Error
 		})
 
+		if errors.Is(err, errTeamUnavailable) {
+			c.JSON(http.StatusGone, gin.H{"error": "This team no longer exists"})
+			return
+		}
 		if err != nil {
 			// Tambahkan log ini untuk debug di masa depan
 			log.Printf("Failed to accept invitation: %v\n", err)
@@ -86,3 +104,199 @@ func RespondToInvitation(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
 	}
 }
+
+var errTeamUnavailable = errors.New("team is deleted")
+
+// joinTeam adalah jalur transaksi bersama untuk bergabung ke tim, dipakai oleh
+// RespondToInvitation maupun JoinTeamWithLink. Langkah khusus (menandai undangan
+// diterima, menambah pemakaian link) dijalankan di transaksi yang sama lewat step.
+func joinTeam(teamID, userID uint, role models.TeamRole, step func(tx *gorm.DB) error) error {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Tim di tempat sampah tidak bisa menerima anggota baru
+		var teamCount int64
+		if err := tx.Model(&models.Team{}).Where("id = ?", teamID).Count(&teamCount).Error; err != nil {
+			return err
+		}
+		if teamCount == 0 {
+			return errTeamUnavailable
+		}
+
+		if err := step(tx); err != nil {
+			return err
+		}
+
+		if err := addTeamMember(tx, teamID, userID, role); err != nil {
+			return err
+		}
+
+		// Undangan lain ke tim yang sama tidak relevan lagi setelah user bergabung
+		return tx.Model(&models.Invitation{}).
+			Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, models.InvitationPending).
+			Update("status", models.InvitationAccepted).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	ws.AppHub.BroadcastToTeam(teamID, "member_joined", gin.H{"team_id": teamID, "user_id": userID, "role": role})
+	ws.AppHub.SubscribeUser(userID, teamID)
+	return nil
+}
+
+// attachPendingInvitations menautkan undangan via email yang masih tertunda ke akun user,
+// sehingga undangan tersebut muncul di GET /api/invitations dan bisa diterima.
+func attachPendingInvitations(tx *gorm.DB, user models.User) error {
+	return tx.Model(&models.Invitation{}).
+		Where("email = ? AND user_id IS NULL AND status = ?", user.Email, models.InvitationPending).
+		Update("user_id", user.ID).Error
+}
+
+// findInvitationByToken memvalidasi token undangan dan mengembalikan undangan yang masih tertunda.
+func findInvitationByToken(token string) (models.Invitation, error) {
+	var invitation models.Invitation
+
+	claims, err := utils.ParseToken(token, utils.TokenTypeInvite)
+	if err != nil {
+		return invitation, err
+	}
+	invitationID, ok := utils.ClaimUint(claims, "invitation_id")
+	email, _ := claims["email"].(string)
+	if !ok || email == "" {
+		return invitation, errors.New("invalid invitation token")
+	}
+
+	err = config.DB.Preload("Team").
+		Where("id = ? AND email = ? AND status = ?", invitationID, email, models.InvitationPending).
+		First(&invitation).Error
+	if err == nil && invitation.IsExpired() {
+		return invitation, errors.New("invitation has expired")
+	}
+	return invitation, err
+}
+
+// ShowInvitationPage menampilkan halaman sederhana untuk link undangan dari email.
+// Rute: GET /auth/invite?token=...
+func ShowInvitationPage(c *gin.Context) {
+	invitation, err := findInvitationByToken(c.Query("token"))
+	if err != nil {
+		c.Data(http.StatusBadRequest, "text/html; charset=utf-8", []byte("<h1>Invalid or Expired Invitation</h1><p>Please ask the team to send you a new invitation.</p>"))
+		return
+	}
+
+	page := "<h1>You're Invited!</h1><p>You have been invited to join the team <b>" + html.EscapeString(invitation.Team.Name) + "</b> on NotedTeam.</p>"
+	page += "<p>Open the NotedTeam app and sign up using <b>" + html.EscapeString(invitation.Email) + "</b>. The invitation will be waiting for you after you sign up.</p>"
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// GetTeamInvitations menampilkan undangan yang pernah dikirim oleh tim.
+// Query opsional ?status=pending|accepted|declined|revoked|expired
+// Rute: GET /api/teams/:teamId/invitations
+func GetTeamInvitations(c *gin.Context) {
+	teamID := c.Param("teamId")
+
+	query := config.DB.Preload("InvitedBy").Where("team_id = ?", teamID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Order("created_at desc").Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// findTeamInvitation mencari undangan berdasarkan :invitationId milik tim pada :teamId.
+func findTeamInvitation(c *gin.Context) (models.Invitation, bool) {
+	var invitation models.Invitation
+	if err := config.DB.Preload("Team").
+		Where("id = ? AND team_id = ?", c.Param("invitationId"), c.Param("teamId")).
+		First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found in this team"})
+		return invitation, false
+	}
+	return invitation, true
+}
+
+// RevokeInvitation membatalkan undangan yang masih tertunda.
+// Rute: DELETE /api/teams/:teamId/invitations/:invitationId
+func RevokeInvitation(c *gin.Context) {
+	invitation, ok := findTeamInvitation(c)
+	if !ok {
+		return
+	}
+	if invitation.Status != models.InvitationPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending invitations can be revoked"})
+		return
+	}
+
+	// Notifikasi undangan di kotak masuk orang yang diundang ikut ditandai dibaca
+	var readIDs []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&invitation).Update("status", models.InvitationRevoked).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Notification{}).
+			Where("invitation_id = ? AND is_read = ?", invitation.ID, false).
+			Pluck("id", &readIDs).Error; err != nil {
+			return err
+		}
+		if len(readIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Notification{}).Where("id IN ?", readIDs).Update("is_read", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	if invitation.UserID != nil && len(readIDs) > 0 {
+		unread := unreadNotificationCount(*invitation.UserID)
+		ws.AppHub.SendToUser(*invitation.UserID, "notifications_read", gin.H{"ids": readIDs, "unread_count": unread})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// ResendInvitation memperpanjang masa berlaku undangan yang tertunda/kedaluwarsa
+// dan mengirim ulang email pemberitahuan ke orang yang diundang.
+// Rute: POST /api/teams/:teamId/invitations/:invitationId/resend
+func ResendInvitation(c *gin.Context) {
+	invitation, ok := findTeamInvitation(c)
+	if !ok {
+		return
+	}
+	if invitation.Status != models.InvitationPending && invitation.Status != models.InvitationExpired {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or expired invitations can be resent"})
+		return
+	}
+
+	expiresAt := time.Now().Add(utils.InvitationTTL)
+	if err := config.DB.Model(&invitation).Updates(map[string]interface{}{
+		"status":     models.InvitationPending,
+		"expires_at": expiresAt,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend invitation"})
+		return
+	}
+
+	inviter := c.MustGet("user").(models.User)
+	if invitation.UserID != nil {
+		// Sudah punya akun: cukup ingatkan bahwa ada undangan di aplikasi
+		go utils.SendInvitationReminderEmail(invitation.Email, invitation.Team.Name, inviter.Name)
+		notifyInvitation(invitation, inviter.ID)
+	} else {
+		token, err := utils.GenerateInviteToken(invitation.ID, invitation.Email, expiresAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation token"})
+			return
+		}
+		go utils.SendTeamInvitationEmail(invitation.Email, invitation.Team.Name, inviter.Name, token)
+	}
+
+	config.DB.Preload("InvitedBy").First(&invitation, invitation.ID)
+	c.JSON(http.StatusOK, gin.H{"data": invitation})
+}
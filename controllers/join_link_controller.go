@@ -0,0 +1,158 @@
+// controllers/join_link_controller.go
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+var errJoinLinkExhausted = errors.New("join link is no longer usable")
+
+// CreateJoinLinkInput mendefinisikan pengaturan link bergabung. Semua field opsional.
+type CreateJoinLinkInput struct {
+	MaxUses     *int            `json:"max_uses" binding:"omitempty,min=1"`
+	ExpiresAt   *time.Time      `json:"expires_at"`
+	DefaultRole models.TeamRole `json:"default_role"`
+}
+
+// CreateJoinLink membuat link bergabung baru untuk tim.
+// Rute: POST /api/teams/:teamId/join-links
+func CreateJoinLink(c *gin.Context) {
+	var input CreateJoinLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	role := input.DefaultRole
+	if role == "" {
+		role = models.RoleEditor
+	}
+	// Pembuat link hanya boleh memberi role di bawah role-nya sendiri (owner tidak pernah bisa diberikan)
+	actorRole := c.MustGet("team_role").(models.TeamRole)
+	if !role.IsValid() || role == models.RoleOwner || !actorRole.Outranks(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid default role for this join link"})
+		return
+	}
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	code, err := generateSecureToken(12)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate join code"})
+		return
+	}
+
+	creatorID, _ := c.Get("user_id")
+	link := models.TeamJoinLink{
+		TeamID:      uint(teamID),
+		Code:        code,
+		CreatedByID: creatorID.(uint),
+		DefaultRole: role,
+		MaxUses:     input.MaxUses,
+		ExpiresAt:   input.ExpiresAt,
+	}
+	if err := config.DB.Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": link})
+}
+
+// GetJoinLinks menampilkan link bergabung tim yang belum dicabut.
+// Rute: GET /api/teams/:teamId/join-links
+func GetJoinLinks(c *gin.Context) {
+	teamID := c.Param("teamId")
+
+	var links []models.TeamJoinLink
+	if err := config.DB.Where("team_id = ? AND revoked_at IS NULL", teamID).Order("created_at desc").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch join links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": links})
+}
+
+// RevokeJoinLink mencabut link bergabung sehingga tidak bisa dipakai lagi.
+// Rute: DELETE /api/teams/:teamId/join-links/:linkId
+func RevokeJoinLink(c *gin.Context) {
+	var link models.TeamJoinLink
+	if err := config.DB.Where("id = ? AND team_id = ? AND revoked_at IS NULL", c.Param("linkId"), c.Param("teamId")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Join link not found in this team"})
+		return
+	}
+
+	if err := config.DB.Model(&link).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke join link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join link revoked"})
+}
+
+// JoinTeamWithLink menambahkan user yang sedang login ke tim pemilik kode.
+// Rute: POST /api/join/:code
+func JoinTeamWithLink(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var link models.TeamJoinLink
+	if err := config.DB.Where("code = ?", c.Param("code")).First(&link).Error; err != nil || !link.IsUsable() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Join link is invalid, expired or has reached its usage limit"})
+		return
+	}
+
+	var memberCount int64
+	config.DB.Model(&models.TeamMember{}).Where("team_id = ? AND user_id = ?", link.TeamID, userID).Count(&memberCount)
+	if memberCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already a member of this team"})
+		return
+	}
+
+	err := joinTeam(link.TeamID, userID.(uint), link.DefaultRole, func(tx *gorm.DB) error {
+		// Tambah pemakaian secara atomik agar batas max_uses tidak terlampaui oleh request bersamaan
+		result := tx.Model(&models.TeamJoinLink{}).
+			Where("id = ? AND revoked_at IS NULL AND (max_uses IS NULL OR use_count < max_uses)", link.ID).
+			UpdateColumn("use_count", gorm.Expr("use_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errJoinLinkExhausted
+		}
+		return nil
+	})
+	if errors.Is(err, errJoinLinkExhausted) {
+		c.JSON(http.StatusGone, gin.H{"error": "Join link has reached its usage limit"})
+		return
+	}
+	if errors.Is(err, errTeamUnavailable) {
+		c.JSON(http.StatusGone, gin.H{"error": "This team no longer exists"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to join team with link: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join team"})
+		return
+	}
+
+	var team models.Team
+	config.DB.First(&team, link.TeamID)
+	c.JSON(http.StatusOK, gin.H{"data": team})
+}
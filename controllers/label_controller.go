@@ -0,0 +1,257 @@
+// controllers/label_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// defaultLabelColor dipakai jika klien tidak mengirim warna.
+const defaultLabelColor = "#9e9e9e"
+
+// CreateLabelInput mendefinisikan data untuk membuat label baru.
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor,len=7"` // Format #rrggbb
+}
+
+// UpdateLabelInput memakai pointer agar field yang tidak dikirim tidak ikut diubah.
+type UpdateLabelInput struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color" binding:"omitempty,hexcolor,len=7"`
+}
+
+// AddTodoLabelInput mendefinisikan label yang akan ditempelkan ke sebuah todo.
+type AddTodoLabelInput struct {
+	LabelID uint `json:"label_id" binding:"required"`
+}
+
+// findTeamLabels memastikan semua labelIDs milik tim lalu mengembalikan datanya.
+func findTeamLabels(teamID uint, labelIDs []uint) ([]models.Label, error) {
+	var labels []models.Label
+	if err := config.DB.Where("team_id = ? AND id IN ?", teamID, labelIDs).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	if len(labels) != len(uniqueIDs(labelIDs)) {
+		return nil, errors.New("All labels must belong to this team")
+	}
+	return labels, nil
+}
+
+// parseLabelFilter membaca ?labels=1,2,3 menjadi daftar ID label.
+func parseLabelFilter(raw string) ([]uint, error) {
+	ids, err := parseIDList(raw)
+	if err != nil {
+		return nil, errors.New("labels must be a comma-separated list of label IDs")
+	}
+	return ids, nil
+}
+
+// labelNameTaken mengecek apakah nama label sudah dipakai label lain di tim yang sama.
+func labelNameTaken(teamID uint, name string, exceptID uint) bool {
+	var count int64
+	config.DB.Model(&models.Label{}).Where("team_id = ? AND name = ? AND id <> ?", teamID, name, exceptID).Count(&count)
+	return count > 0
+}
+
+// findTeamLabel mencari label :labelId milik tim :teamId.
+func findTeamLabel(c *gin.Context) (models.Label, bool) {
+	var label models.Label
+	if err := config.DB.Where("id = ? AND team_id = ?", c.Param("labelId"), c.Param("teamId")).First(&label).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found in this team"})
+		return label, false
+	}
+	return label, true
+}
+
+// GetTeamLabels mengambil semua label milik tim.
+// Rute: GET /api/teams/:teamId/labels
+func GetTeamLabels(c *gin.Context) {
+	var labels []models.Label
+	if err := config.DB.Where("team_id = ?", c.Param("teamId")).Order("name asc").Find(&labels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch labels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": labels})
+}
+
+// CreateLabel membuat label baru untuk tim.
+// Rute: POST /api/teams/:teamId/labels
+func CreateLabel(c *gin.Context) {
+	var input CreateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Label name cannot be empty"})
+		return
+	}
+	if labelNameTaken(uint(teamID), name, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A label with this name already exists in this team"})
+		return
+	}
+
+	color := strings.ToLower(input.Color)
+	if color == "" {
+		color = defaultLabelColor
+	}
+
+	label := models.Label{TeamID: uint(teamID), Name: name, Color: color}
+	if err := config.DB.Create(&label).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create label"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(label.TeamID, "label_created", label)
+
+	c.JSON(http.StatusCreated, gin.H{"data": label})
+}
+
+// UpdateLabel mengubah nama dan/atau warna label.
+// Rute: PUT /api/teams/:teamId/labels/:labelId
+func UpdateLabel(c *gin.Context) {
+	var input UpdateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, ok := findTeamLabel(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Label name cannot be empty"})
+			return
+		}
+		if labelNameTaken(label.TeamID, name, label.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A label with this name already exists in this team"})
+			return
+		}
+		updates["name"] = name
+	}
+	if input.Color != nil {
+		updates["color"] = strings.ToLower(*input.Color)
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&label).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update label"})
+			return
+		}
+		config.DB.First(&label, label.ID)
+	}
+
+	ws.AppHub.BroadcastToTeam(label.TeamID, "label_updated", label)
+
+	c.JSON(http.StatusOK, gin.H{"data": label})
+}
+
+// DeleteLabel menghapus label dan melepasnya dari semua todo.
+// Rute: DELETE /api/teams/:teamId/labels/:labelId
+func DeleteLabel(c *gin.Context) {
+	label, ok := findTeamLabel(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM todo_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&label).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete label"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(label.TeamID, "label_deleted", gin.H{"id": label.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+// AddTodoLabel menempelkan label tim ke sebuah todo.
+// Rute: POST /api/teams/:teamId/todos/:todoId/labels
+func AddTodoLabel(c *gin.Context) {
+	var input AddTodoLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	labels, err := findTeamLabels(todo.TeamID, []uint{input.LabelID})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.DB.Model(&todo).Association("Labels").Append(&labels); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add label"})
+		return
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_updated", todo)
+
+	c.JSON(http.StatusOK, gin.H{"data": todo})
+}
+
+// RemoveTodoLabel melepas label dari sebuah todo.
+// Rute: DELETE /api/teams/:teamId/todos/:todoId/labels/:labelId
+func RemoveTodoLabel(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	labelID, err := strconv.ParseUint(c.Param("labelId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid label ID"})
+		return
+	}
+
+	if err := config.DB.Model(&todo).Association("Labels").Delete(&models.Label{ID: uint(labelID)}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove label"})
+		return
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_updated", todo)
+
+	c.JSON(http.StatusOK, gin.H{"data": todo})
+}
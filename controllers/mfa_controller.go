@@ -0,0 +1,369 @@
+// controllers/mfa_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+)
+
+const (
+	totpIssuer        = "NotedTeam"
+	recoveryCodeCount = 10
+
+	// Batas kode 2FA yang salah per user dalam mfaLockoutWindow, dihitung lintas token
+	// tantangan agar login ulang dengan password tidak mereset jatah tebakan.
+	maxMFAAttempts   = 5
+	mfaLockoutWindow = 15 * time.Minute
+)
+
+var (
+	errInvalidMFACode  = errors.New("invalid mfa code")
+	errInvalidMFAToken = errors.New("invalid mfa token")
+)
+
+// MFACodeInput dipakai untuk konfirmasi 2FA dan pembuatan ulang kode pemulihan.
+type MFACodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableMFAInput mewajibkan password dan kode 2FA sebelum 2FA dimatikan.
+type DisableMFAInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFALoginInput adalah langkah kedua login untuk akun yang mengaktifkan 2FA.
+type MFALoginInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // Kode TOTP 6 digit atau kode pemulihan
+}
+
+// currentUser mengambil versi terbaru user yang sedang login dari database.
+func currentUser(c *gin.Context) (models.User, error) {
+	userID, _ := c.Get("user_id")
+	var user models.User
+	err := config.DB.First(&user, userID).Error
+	return user, err
+}
+
+// generateRecoveryCodes mengganti semua kode pemulihan user dengan yang baru
+// dan mengembalikan versi teks biasanya (hanya ditampilkan sekali).
+func generateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		record := models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// startMFAChallenge mencatat tantangan 2FA baru lalu menerbitkan token tantangannya.
+// Tantangan lama milik user yang sudah di luar jendela lockout sekalian dibersihkan.
+func startMFAChallenge(userID uint, deviceName string) (string, error) {
+	tokenID, err := utils.GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	config.DB.Where("user_id = ? AND created_at < ?", userID, now.Add(-mfaLockoutWindow)).Delete(&models.MFAChallenge{})
+
+	challenge := models.MFAChallenge{UserID: userID, TokenID: tokenID, ExpiresAt: now.Add(utils.MFATokenTTL)}
+	if err := config.DB.Create(&challenge).Error; err != nil {
+		return "", err
+	}
+	return utils.GenerateMFAToken(userID, deviceName, tokenID)
+}
+
+// recentFailedMFAAttempts menjumlahkan kode 2FA yang salah milik user dalam mfaLockoutWindow.
+func recentFailedMFAAttempts(tx *gorm.DB, userID uint) int {
+	var failed int
+	tx.Model(&models.MFAChallenge{}).
+		Where("user_id = ? AND created_at > ?", userID, time.Now().Add(-mfaLockoutWindow)).
+		Select("COALESCE(SUM(failed_attempts), 0)").
+		Scan(&failed)
+	return failed
+}
+
+// verifySecondFactor menerima kode TOTP atau kode pemulihan yang belum dipakai.
+// Kode yang berhasil dipakai langsung "dihanguskan" agar tidak bisa diulang.
+func verifySecondFactor(tx *gorm.DB, user *models.User, code string) bool {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		// Update bersyarat: jika ada request lain yang memakai kode yang sama lebih dulu, tolak.
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			UpdateColumn("totp_last_step", step)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false
+		}
+		user.TOTPLastStep = step
+		return true
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// SetupMFA membuat secret TOTP baru (belum aktif) beserta URI otpauth untuk QR code.
+// Rute: POST /api/me/2fa/setup
+func SetupMFA(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPProvisioningURI(secret, user.Email, totpIssuer),
+	}})
+}
+
+// ConfirmMFA mengaktifkan 2FA setelah user membuktikan authenticator-nya bekerja,
+// lalu mengembalikan kode pemulihan.
+// Rute: POST /api/me/2fa/confirm
+func ConfirmMFA(c *gin.Context) {
+	var input MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Call the setup endpoint first"})
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, input.Code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{"totp_enabled": true, "totp_last_step": step}).Error; err != nil {
+			return err
+		}
+		var genErr error
+		codes, genErr = generateRecoveryCodes(tx, user.ID)
+		return genErr
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store these recovery codes somewhere safe.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableMFA mematikan 2FA. Membutuhkan password dan kode TOTP/pemulihan yang valid.
+// Rute: POST /api/me/2fa/disable
+func DisableMFA(c *gin.Context) {
+	var input DisableMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if !verifySecondFactor(tx, &user, input.Code) {
+			return errInvalidMFACode
+		}
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if errors.Is(err, errInvalidMFACode) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes membuat ulang kode pemulihan; kode lama tidak berlaku lagi.
+// Rute: POST /api/me/2fa/recovery-codes
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if !verifySecondFactor(tx, &user, input.Code) {
+			return errInvalidMFACode
+		}
+		var genErr error
+		codes, genErr = generateRecoveryCodes(tx, user.ID)
+		return genErr
+	})
+	if errors.Is(err, errInvalidMFACode) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// LoginMFA menukar token tantangan dari Login dengan kode 2FA yang valid
+// untuk mendapatkan sesi & JWT yang sebenarnya.
+// Rute: POST /auth/login/mfa
+func LoginMFA(c *gin.Context) {
+	var input MFALoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.ParseToken(input.MFAToken, utils.TokenTypeMFA)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token. Please log in again."})
+		return
+	}
+	userID, ok := utils.ClaimUint(claims, "user_id")
+	tokenID, _ := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA token"})
+		return
+	}
+
+	var user models.User
+	locked, invalidCode := false, false
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris user agar percobaan paralel dihitung satu per satu
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil || !user.TOTPEnabled {
+			return errInvalidMFAToken
+		}
+		var challenge models.MFAChallenge
+		if err := tx.Where("token_id = ? AND user_id = ? AND used_at IS NULL AND expires_at > ?", tokenID, user.ID, time.Now()).
+			First(&challenge).Error; err != nil {
+			return errInvalidMFAToken
+		}
+		if recentFailedMFAAttempts(tx, user.ID) >= maxMFAAttempts {
+			locked = true
+			return nil
+		}
+
+		if !verifySecondFactor(tx, &user, input.Code) {
+			// Kembalikan nil agar transaksi tetap commit dan percobaan gagal tersimpan
+			invalidCode = true
+			return tx.Model(&challenge).UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error
+		}
+		return tx.Model(&challenge).Update("used_at", time.Now()).Error
+	})
+	if locked {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many invalid authentication codes. Please try again later."})
+		return
+	}
+	if errors.Is(err, errInvalidMFAToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify authentication code"})
+		return
+	}
+	if invalidCode {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	deviceName, _ := claims["device_name"].(string)
+	tokens, err := startSession(user, sessionMetaFromRequest(c, deviceName))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,222 @@
+// controllers/notification_controller.go
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
+)
+
+const (
+	defaultNotificationPageSize = 30
+	maxNotificationPageSize     = 100
+)
+
+// notify menyimpan notifikasi lalu mengirimkannya lewat koneksi websocket pribadi
+// setiap penerima. Kegagalan hanya dicatat di log agar tidak menggagalkan aksi utamanya.
+func notify(notifications ...models.Notification) {
+	created, err := models.CreateNotifications(config.DB, notifications)
+	if err != nil {
+		log.Printf("Failed to create notifications: %v", err)
+		return
+	}
+	for _, n := range created {
+		ws.AppHub.SendToUser(n.UserID, "notification", n)
+	}
+}
+
+// unreadNotificationCount menghitung notifikasi user yang belum dibaca.
+func unreadNotificationCount(userID uint) int64 {
+	var count int64
+	config.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count)
+	return count
+}
+
+// notifyInvitation memberi tahu user terdaftar bahwa ia diundang ke sebuah tim.
+func notifyInvitation(invitation models.Invitation, actorID uint) {
+	if invitation.UserID == nil {
+		return
+	}
+	notify(models.Notification{
+		UserID:       *invitation.UserID,
+		Type:         models.NotificationInvitation,
+		ActorID:      &actorID,
+		TeamID:       &invitation.TeamID,
+		InvitationID: &invitation.ID,
+	})
+}
+
+// notifyAssigned memberi tahu anggota yang baru ditugaskan ke sebuah todo.
+func notifyAssigned(todo models.Todo, actorID uint, userIDs ...uint) {
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = models.Notification{
+			UserID:  userID,
+			Type:    models.NotificationAssigned,
+			ActorID: &actorID,
+			TeamID:  &todo.TeamID,
+			TodoID:  &todo.ID,
+		}
+	}
+	notify(notifications...)
+}
+
+// notifyComment memberi tahu user yang di-mention (@email) di komentar, lalu pembuat todo,
+// anggota yang ditugaskan, dan penulis komentar yang dibalas. Untuk komentar yang diedit,
+// previousBody berisi isi lama dan hanya mention baru yang diberi tahu.
+func notifyComment(comment models.Comment, todo models.Todo, previousBody *string) {
+	actorID, teamID, todoID, commentID := comment.AuthorID, todo.TeamID, todo.ID, comment.ID
+	base := models.Notification{ActorID: &actorID, TeamID: &teamID, TodoID: &todoID, CommentID: &commentID}
+
+	// Mention hanya berlaku untuk anggota tim
+	skip := map[string]bool{}
+	if previousBody != nil {
+		for _, email := range utils.ExtractMentions(*previousBody) {
+			skip[email] = true
+		}
+	}
+	var emails []string
+	for _, email := range utils.ExtractMentions(comment.Body) {
+		if !skip[email] {
+			emails = append(emails, email)
+		}
+	}
+	var mentionedIDs []uint
+	if len(emails) > 0 {
+		config.DB.Table("users").
+			Joins("JOIN team_members ON team_members.user_id = users.id AND team_members.team_id = ?", teamID).
+			Where("LOWER(users.email) IN ?", emails).
+			Pluck("users.id", &mentionedIDs)
+	}
+
+	notified := map[uint]bool{}
+	var notifications []models.Notification
+	add := func(userID uint, kind string) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		n := base
+		n.UserID = userID
+		n.Type = kind
+		notifications = append(notifications, n)
+	}
+	for _, id := range mentionedIDs {
+		add(id, models.NotificationMention)
+	}
+
+	if previousBody == nil {
+		add(todo.CreatorID, models.NotificationComment)
+		var assigneeIDs []uint
+		config.DB.Table("todo_assignees").Where("todo_id = ?", todo.ID).Pluck("user_id", &assigneeIDs)
+		for _, id := range assigneeIDs {
+			add(id, models.NotificationComment)
+		}
+		if comment.ParentID != nil {
+			var parent models.Comment
+			if config.DB.First(&parent, *comment.ParentID).Error == nil {
+				add(parent.AuthorID, models.NotificationComment)
+			}
+		}
+	}
+
+	notify(notifications...)
+}
+
+// GetMyNotifications mengambil kotak masuk notifikasi user, terbaru lebih dulu.
+// Query opsional: ?unread=true, ?limit=, dan ?before_id=<next_before_id> untuk halaman berikutnya.
+// Rute: GET /api/notifications
+func GetMyNotifications(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	limit := defaultNotificationPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		if n > maxNotificationPageSize {
+			n = maxNotificationPageSize
+		}
+		limit = n
+	}
+
+	query := models.PreloadNotification(config.DB).Where("user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("is_read = ?", false)
+	}
+	if raw := c.Query("before_id"); raw != "" {
+		beforeID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before_id"})
+			return
+		}
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("id desc").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch notifications"})
+		return
+	}
+
+	var nextBeforeID *uint
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		nextBeforeID = &notifications[limit-1].ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":           notifications,
+		"unread_count":   unreadNotificationCount(userID),
+		"next_before_id": nextBeforeID,
+	})
+}
+
+// MarkNotificationRead menandai satu notifikasi sudah dibaca.
+// Rute: POST /api/notifications/:notificationId/read
+func MarkNotificationRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	var notification models.Notification
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("notificationId"), userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if !notification.Read {
+		if err := config.DB.Model(&notification).Update("is_read", true).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+			return
+		}
+	}
+
+	// Perangkat lain milik user ikut memperbarui tanda belum dibaca
+	unread := unreadNotificationCount(userID)
+	ws.AppHub.SendToUser(userID, "notifications_read", gin.H{"ids": []uint{notification.ID}, "unread_count": unread})
+
+	models.PreloadNotification(config.DB).First(&notification, notification.ID)
+	c.JSON(http.StatusOK, gin.H{"data": notification, "unread_count": unread})
+}
+
+// MarkAllNotificationsRead menandai semua notifikasi user sudah dibaca.
+// Rute: POST /api/notifications/read-all
+func MarkAllNotificationsRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	result := config.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Update("is_read", true)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	ws.AppHub.SendToUser(userID, "notifications_read", gin.H{"all": true, "unread_count": 0})
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": result.RowsAffected})
+}
@@ -0,0 +1,35 @@
+// controllers/presence_controller.go
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// GetTeamPresence mengambil anggota tim yang sedang terhubung lewat websocket, beserta
+// todo yang sedang mereka edit. Perubahan berikutnya dikirim lewat event presence_joined,
+// presence_left, editing_started, dan editing_stopped.
+// Rute: GET /api/teams/:teamId/presence
+func GetTeamPresence(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Team ID"})
+		return
+	}
+
+	userIDs, editing := ws.AppHub.Presence(uint(teamID))
+	users := []models.User{}
+	if len(userIDs) > 0 {
+		if err := config.DB.Where("id IN ?", userIDs).Order("id").Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch online members"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"users": users, "editing": editing}})
+}
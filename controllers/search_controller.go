@@ -0,0 +1,176 @@
+// controllers/search_controller.go
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	snippetRadius      = 60
+)
+
+// SearchResult adalah satu hasil pencarian, baik todo maupun komentar pada todo.
+type SearchResult struct {
+	Type      string            `json:"type"` // "todo" atau "comment"
+	Score     float64           `json:"score"`
+	TeamID    uint              `json:"team_id"`
+	TeamName  string            `json:"team_name"`
+	TodoID    uint              `json:"todo_id"`
+	TodoTitle string            `json:"todo_title"`
+	Status    models.StatusType `json:"status"`
+	CommentID *uint             `json:"comment_id,omitempty"`
+	Snippet   string            `json:"snippet"` // HTML aman, kata kunci dibungkus <mark>
+}
+
+// searchRow menampung hasil query mentah sebelum snippet dibuat.
+type searchRow struct {
+	ID          uint
+	TodoID      uint
+	Title       string
+	Description string
+	Body        string
+	Status      models.StatusType
+	TeamID      uint
+	TeamName    string
+	Score       float64
+}
+
+// Search mencari todo (judul & deskripsi) dan komentar di semua tim milik user
+// menggunakan index FULLTEXT MySQL. Hasil diurutkan berdasarkan relevansi.
+// Query: q (wajib), team_id, status (boleh dipisah koma), type=all|todo|comment, limit (maks 50)
+// Rute: GET /api/search
+func Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		if n > maxSearchLimit {
+			n = maxSearchLimit
+		}
+		limit = n
+	}
+
+	resultType := c.DefaultQuery("type", "all")
+	if resultType != "all" && resultType != "todo" && resultType != "comment" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of all, todo, comment"})
+		return
+	}
+
+	statuses := splitQueryList(c.Query("status"))
+	for _, status := range statuses {
+		switch models.StatusType(status) {
+		case models.StatusPending, models.StatusWorking, models.StatusCompleted:
+			// Status valid
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter: " + status})
+			return
+		}
+	}
+
+	userID, _ := c.Get("user_id")
+
+	// Hanya todo di tim tempat user menjadi anggota
+	scope := func(query *gorm.DB) *gorm.DB {
+		query = query.
+			Joins("JOIN teams ON teams.id = todos.team_id AND teams.deleted_at IS NULL").
+			Joins("JOIN team_members ON team_members.team_id = todos.team_id AND team_members.user_id = ?", userID)
+		query = query.Where("todos.deleted_at IS NULL")
+		if teamID := c.Query("team_id"); teamID != "" {
+			query = query.Where("todos.team_id = ?", teamID)
+		}
+		if len(statuses) > 0 {
+			query = query.Where("todos.status IN ?", statuses)
+		}
+		return query
+	}
+
+	var rows []searchRow
+	terms := utils.SearchTerms(q)
+	results := []SearchResult{}
+
+	if resultType != "comment" {
+		match := "MATCH(todos.title, todos.description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+		if err := scope(config.DB.Table("todos")).
+			Select("todos.id, todos.id AS todo_id, todos.title, todos.description, todos.status, todos.team_id, teams.name AS team_name, "+match+" AS score", q).
+			Where(match, q).
+			Order("score desc").
+			Limit(limit).
+			Scan(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+			return
+		}
+		for _, row := range rows {
+			// Tampilkan potongan deskripsi jika kata kunci ada di sana, jika tidak pakai judul
+			snippet := utils.HighlightSnippet(row.Description, terms, snippetRadius)
+			if !strings.Contains(snippet, "<mark>") {
+				snippet = utils.HighlightSnippet(row.Title, terms, snippetRadius)
+			}
+			results = append(results, SearchResult{
+				Type:      "todo",
+				Score:     row.Score,
+				TeamID:    row.TeamID,
+				TeamName:  row.TeamName,
+				TodoID:    row.TodoID,
+				TodoTitle: row.Title,
+				Status:    row.Status,
+				Snippet:   snippet,
+			})
+		}
+	}
+
+	if resultType != "todo" {
+		rows = nil
+		match := "MATCH(comments.body) AGAINST (? IN NATURAL LANGUAGE MODE)"
+		if err := scope(config.DB.Table("comments").Joins("JOIN todos ON todos.id = comments.todo_id")).
+			Select("comments.id, comments.todo_id, todos.title, comments.body, todos.status, todos.team_id, teams.name AS team_name, "+match+" AS score", q).
+			Where(match, q).
+			Order("score desc").
+			Limit(limit).
+			Scan(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+			return
+		}
+		for _, row := range rows {
+			commentID := row.ID
+			results = append(results, SearchResult{
+				Type:      "comment",
+				Score:     row.Score,
+				TeamID:    row.TeamID,
+				TeamName:  row.TeamName,
+				TodoID:    row.TodoID,
+				TodoTitle: row.Title,
+				Status:    row.Status,
+				CommentID: &commentID,
+				Snippet:   utils.HighlightSnippet(row.Body, terms, snippetRadius),
+			})
+		}
+	}
+
+	// Gabungkan hasil todo dan komentar berdasarkan relevansi
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
@@ -0,0 +1,259 @@
+// controllers/session_controller.go
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
+)
+
+// RefreshTokenInput dipakai oleh endpoint refresh dan logout.
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+// issueRefreshToken membuat refresh token baru untuk sebuah sesi dan menyimpan hash-nya.
+func issueRefreshToken(tx *gorm.DB, sessionID uint) (string, error) {
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	stored := models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(refreshToken),
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// tokenPairResponse menyusun respons standar berisi access token & refresh token.
+func tokenPairResponse(userID, sessionID uint, refreshToken string) (gin.H, error) {
+	accessToken, err := utils.GenerateToken(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// sessionMeta berisi informasi perangkat yang dicatat pada sebuah sesi.
+type sessionMeta struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// sessionMetaFromRequest mengambil informasi perangkat dari request yang sedang diproses.
+func sessionMetaFromRequest(c *gin.Context, deviceName string) sessionMeta {
+	return sessionMeta{
+		DeviceName: truncate(deviceName, 100),
+		UserAgent:  truncate(c.Request.UserAgent(), 255),
+		IPAddress:  c.ClientIP(),
+	}
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+// startSession membuat sesi baru untuk user yang berhasil login
+// dan mengembalikan pasangan token pertamanya.
+func startSession(user models.User, meta sessionMeta) (gin.H, error) {
+	var response gin.H
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session := models.Session{
+			UserID:     user.ID,
+			ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+			DeviceName: meta.DeviceName,
+			UserAgent:  meta.UserAgent,
+			IPAddress:  meta.IPAddress,
+			LastSeenAt: &now,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		refreshToken, err := issueRefreshToken(tx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		response, err = tokenPairResponse(user.ID, session.ID, refreshToken)
+		return err
+	})
+	return response, err
+}
+
+// revokeSession menandai sebuah sesi sebagai dicabut sehingga semua access token
+// dan refresh token miliknya tidak lagi diterima.
+func revokeSession(tx *gorm.DB, sessionID uint) error {
+	return tx.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeUserSessions mencabut semua sesi aktif milik user dalam satu UPDATE.
+func revokeUserSessions(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RefreshAccessToken menukar refresh token dengan pasangan token baru (rotasi).
+// Jika refresh token yang sudah pernah dipakai dikirim lagi, seluruh sesi dicabut.
+// Rute: POST /auth/refresh
+func RefreshAccessToken(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var response gin.H
+	var sessionID uint
+	reuseDetected := false
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris token agar dua permintaan refresh yang bersamaan tidak sama-sama lolos
+		var stored models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(input.RefreshToken)).
+			First(&stored).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+
+		var session models.Session
+		if err := tx.First(&session, stored.SessionID).Error; err != nil || !session.IsActive() {
+			return errInvalidRefreshToken
+		}
+		sessionID = session.ID
+
+		if stored.UsedAt != nil {
+			// Token lama dipakai ulang: kemungkinan besar token dicuri.
+			// Cabut seluruh keluarga sesi, lalu commit agar pencabutan tersimpan.
+			reuseDetected = true
+			return revokeSession(tx, session.ID)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&stored).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"ip_address":   c.ClientIP(),
+		}).Error; err != nil {
+			return err
+		}
+
+		refreshToken, err := issueRefreshToken(tx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		response, err = tokenPairResponse(session.UserID, session.ID, refreshToken)
+		return err
+	})
+
+	if reuseDetected && err == nil {
+		log.Printf("Refresh token reuse detected, session %d revoked", sessionID)
+		ws.AppHub.DisconnectSession(sessionID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used. Please log in again."})
+		return
+	}
+	if errors.Is(err, errInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Logout mencabut sesi milik refresh token yang dikirim.
+// Rute: POST /auth/logout
+func Logout(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", utils.HashToken(input.RefreshToken)).First(&stored).Error; err == nil {
+		if err := revokeSession(config.DB, stored.SessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+		ws.AppHub.DisconnectSession(stored.SessionID)
+	}
+
+	// Tetap balas sukses walaupun token tidak dikenal, agar logout bersifat idempoten
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetMySessions menampilkan semua sesi login yang masih aktif milik user.
+// Rute: GET /api/me/sessions
+func GetMySessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	currentSessionID, _ := c.Get("session_id")
+
+	var sessions []models.Session
+	if err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch sessions"})
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID.(uint)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// RevokeMySession mencabut salah satu sesi milik user dan memutus koneksi websocket-nya.
+// Rute: DELETE /api/me/sessions/:sessionId
+func RevokeMySession(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID := c.Param("sessionId")
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeSession(config.DB, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	ws.AppHub.DisconnectSession(session.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
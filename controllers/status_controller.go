@@ -0,0 +1,450 @@
+// controllers/status_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+var errTransitionNotAllowed = errors.New("This status change is not allowed by the team's workflow")
+
+// CreateTeamStatusInput mendefinisikan data untuk menambah status alur kerja.
+type CreateTeamStatusInput struct {
+	Name   string `json:"name" binding:"required,max=50"`
+	IsDone bool   `json:"is_done"`
+}
+
+// UpdateTeamStatusInput memakai pointer agar field yang tidak dikirim tidak ikut diubah.
+type UpdateTeamStatusInput struct {
+	Name   *string `json:"name" binding:"omitempty,min=1,max=50"`
+	IsDone *bool   `json:"is_done"`
+}
+
+// ReorderTeamStatusesInput berisi semua ID status tim dalam urutan yang baru.
+type ReorderTeamStatusesInput struct {
+	StatusIDs []uint `json:"status_ids" binding:"required"`
+}
+
+// StatusTransitionInput adalah satu perpindahan status yang diizinkan.
+type StatusTransitionInput struct {
+	FromStatusID uint `json:"from_status_id" binding:"required"`
+	ToStatusID   uint `json:"to_status_id" binding:"required"`
+}
+
+// SetStatusTransitionsInput menggantikan seluruh daftar transisi tim.
+// Daftar kosong berarti semua perpindahan status diizinkan.
+type SetStatusTransitionsInput struct {
+	Transitions []StatusTransitionInput `json:"transitions"`
+}
+
+// --- Fungsi Bantuan ---
+
+// teamStatuses mengambil status alur kerja tim sesuai urutan kolom.
+func teamStatuses(teamID uint) ([]models.TeamStatus, error) {
+	var statuses []models.TeamStatus
+	err := config.DB.Where("team_id = ?", teamID).Order("position asc, id asc").Find(&statuses).Error
+	return statuses, err
+}
+
+// resolveTeamStatus menentukan status tujuan dari status_id, atau dari status lama
+// (pending/working/completed) untuk klien yang belum mengenal status tim.
+func resolveTeamStatus(teamID uint, statusID *uint, legacy *models.StatusType) (models.TeamStatus, error) {
+	statuses, err := teamStatuses(teamID)
+	if err != nil {
+		return models.TeamStatus{}, err
+	}
+	if len(statuses) == 0 {
+		return models.TeamStatus{}, errors.New("This team has no workflow statuses")
+	}
+
+	if statusID != nil {
+		for _, status := range statuses {
+			if status.ID == *statusID {
+				return status, nil
+			}
+		}
+		return models.TeamStatus{}, errors.New("Status not found in this team")
+	}
+
+	if legacy != nil {
+		for i, status := range statuses {
+			switch *legacy {
+			case models.StatusPending:
+				return statuses[0], nil
+			case models.StatusCompleted:
+				if status.IsDone {
+					return status, nil
+				}
+			case models.StatusWorking:
+				if i > 0 && !status.IsDone {
+					return status, nil
+				}
+			default:
+				return models.TeamStatus{}, errors.New("Invalid status")
+			}
+		}
+		return models.TeamStatus{}, errors.New("This team has no status matching '" + string(*legacy) + "'")
+	}
+
+	// Tanpa pilihan eksplisit, todo baru masuk ke kolom pertama
+	return statuses[0], nil
+}
+
+// legacyStatusOf menghitung nilai Todo.Status lama untuk sebuah status tim.
+func legacyStatusOf(status models.TeamStatus) models.StatusType {
+	statuses, err := teamStatuses(status.TeamID)
+	if err != nil || len(statuses) == 0 {
+		return models.StatusWorking
+	}
+	return status.LegacyStatus(statuses[0])
+}
+
+// canTransition mengecek apakah todo boleh berpindah dari status from ke status to.
+func canTransition(teamID uint, from *uint, to uint) bool {
+	if from == nil || *from == to {
+		return true
+	}
+
+	var total, allowed int64
+	config.DB.Model(&models.StatusTransition{}).Where("team_id = ?", teamID).Count(&total)
+	if total == 0 {
+		return true
+	}
+	config.DB.Model(&models.StatusTransition{}).
+		Where("team_id = ? AND from_status_id = ? AND to_status_id = ?", teamID, *from, to).
+		Count(&allowed)
+	return allowed > 0
+}
+
+// broadcastWorkflow mengirim status dan transisi terbaru tim ke semua anggota.
+func broadcastWorkflow(teamID uint) {
+	statuses, _ := teamStatuses(teamID)
+	var transitions []models.StatusTransition
+	config.DB.Where("team_id = ?", teamID).Find(&transitions)
+
+	ws.AppHub.BroadcastToTeam(teamID, "workflow_updated", gin.H{
+		"team_id":     teamID,
+		"statuses":    statuses,
+		"transitions": transitions,
+	})
+}
+
+// findTeamStatus mencari status :statusId milik tim :teamId.
+func findTeamStatus(c *gin.Context) (models.TeamStatus, bool) {
+	var status models.TeamStatus
+	if err := config.DB.Where("id = ? AND team_id = ?", c.Param("statusId"), c.Param("teamId")).First(&status).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Status not found in this team"})
+		return status, false
+	}
+	return status, true
+}
+
+// --- Fungsi Controller ---
+
+// GetTeamStatuses mengambil status alur kerja tim sesuai urutan kolom.
+// Rute: GET /api/teams/:teamId/statuses
+func GetTeamStatuses(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	statuses, err := teamStatuses(uint(teamID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch statuses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// CreateTeamStatus menambahkan status baru di kolom paling akhir.
+// Rute: POST /api/teams/:teamId/statuses
+func CreateTeamStatus(c *gin.Context) {
+	var input CreateTeamStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status name cannot be empty"})
+		return
+	}
+
+	var maxPosition *int
+	config.DB.Model(&models.TeamStatus{}).Where("team_id = ?", teamID).Select("MAX(position)").Scan(&maxPosition)
+	position := 0
+	if maxPosition != nil {
+		position = *maxPosition + 1
+	}
+
+	status := models.TeamStatus{TeamID: uint(teamID), Name: name, IsDone: input.IsDone, Position: position}
+	if err := config.DB.Create(&status).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create status"})
+		return
+	}
+
+	broadcastWorkflow(status.TeamID)
+
+	c.JSON(http.StatusCreated, gin.H{"data": status})
+}
+
+// UpdateTeamStatus mengubah nama status dan/atau kategori selesai.
+// Rute: PUT /api/teams/:teamId/statuses/:statusId
+func UpdateTeamStatus(c *gin.Context) {
+	var input UpdateTeamStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, ok := findTeamStatus(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Status name cannot be empty"})
+			return
+		}
+		updates["name"] = name
+	}
+	if input.IsDone != nil {
+		updates["is_done"] = *input.IsDone
+	}
+
+	if len(updates) > 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&status).Updates(updates).Error; err != nil {
+				return err
+			}
+			return models.SyncLegacyStatuses(tx, status.TeamID)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+			return
+		}
+		config.DB.First(&status, status.ID)
+	}
+
+	broadcastWorkflow(status.TeamID)
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// ReorderTeamStatuses menyimpan urutan kolom status yang baru.
+// status_ids harus berisi semua status tim tepat satu kali.
+// Rute: PUT /api/teams/:teamId/statuses/order
+func ReorderTeamStatuses(c *gin.Context) {
+	var input ReorderTeamStatusesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var existingIDs []uint
+	config.DB.Model(&models.TeamStatus{}).Where("team_id = ?", teamID).Pluck("id", &existingIDs)
+	existing := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+	valid := len(uniqueIDs(input.StatusIDs)) == len(input.StatusIDs) && len(input.StatusIDs) == len(existingIDs)
+	for _, id := range input.StatusIDs {
+		valid = valid && existing[id]
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status_ids must contain every status of this team exactly once"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range input.StatusIDs {
+			if err := tx.Model(&models.TeamStatus{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		// Status pertama menentukan siapa yang dianggap 'pending'
+		return models.SyncLegacyStatuses(tx, uint(teamID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder statuses"})
+		return
+	}
+
+	broadcastWorkflow(uint(teamID))
+
+	statuses, _ := teamStatuses(uint(teamID))
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// DeleteTeamStatus menghapus sebuah status. Jika masih ada todo di status tersebut,
+// klien wajib mengirim ?move_to=<statusId> sebagai tujuan pemindahan todo.
+// Rute: DELETE /api/teams/:teamId/statuses/:statusId
+func DeleteTeamStatus(c *gin.Context) {
+	status, ok := findTeamStatus(c)
+	if !ok {
+		return
+	}
+
+	var statusCount, todoCount int64
+	config.DB.Model(&models.TeamStatus{}).Where("team_id = ?", status.TeamID).Count(&statusCount)
+	if statusCount <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A team must keep at least one status"})
+		return
+	}
+
+	config.DB.Model(&models.Todo{}).Where("status_id = ?", status.ID).Count(&todoCount)
+	var moveTo *models.TeamStatus
+	if todoCount > 0 {
+		raw := c.Query("move_to")
+		if raw == "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "This status still has todos; provide ?move_to=<statusId> to move them"})
+			return
+		}
+		targetID, err := strconv.ParseUint(raw, 10, 32)
+		id := uint(targetID)
+		if err != nil || id == status.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "move_to must be another status of this team"})
+			return
+		}
+		target, err := resolveTeamStatus(status.TeamID, &id, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "move_to must be another status of this team"})
+			return
+		}
+		moveTo = &target
+	}
+
+	// Todo di tempat sampah juga harus dipindahkan; tanpa move_to pakai kolom pertama yang tersisa
+	if moveTo == nil {
+		var fallback models.TeamStatus
+		if err := config.DB.Where("team_id = ? AND id <> ?", status.TeamID, status.ID).Order("position asc, id asc").First(&fallback).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete status"})
+			return
+		}
+		moveTo = &fallback
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Todo{}).Where("status_id = ?", status.ID).Updates(map[string]interface{}{
+			"status_id": moveTo.ID,
+			"version":   gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("from_status_id = ? OR to_status_id = ?", status.ID, status.ID).Delete(&models.StatusTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&status).Error; err != nil {
+			return err
+		}
+		return models.SyncLegacyStatuses(tx, status.TeamID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete status"})
+		return
+	}
+
+	broadcastWorkflow(status.TeamID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Status deleted successfully"})
+}
+
+// GetStatusTransitions mengambil transisi status yang diizinkan di tim.
+// Daftar kosong berarti semua perpindahan diizinkan.
+// Rute: GET /api/teams/:teamId/statuses/transitions
+func GetStatusTransitions(c *gin.Context) {
+	var transitions []models.StatusTransition
+	if err := config.DB.Where("team_id = ?", c.Param("teamId")).Find(&transitions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch transitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": transitions})
+}
+
+// SetStatusTransitions menggantikan seluruh daftar transisi status tim.
+// Rute: PUT /api/teams/:teamId/statuses/transitions
+func SetStatusTransitions(c *gin.Context) {
+	var input SetStatusTransitionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var statusIDs []uint
+	config.DB.Model(&models.TeamStatus{}).Where("team_id = ?", teamID).Pluck("id", &statusIDs)
+	inTeam := make(map[uint]bool, len(statusIDs))
+	for _, id := range statusIDs {
+		inTeam[id] = true
+	}
+
+	transitions := make([]models.StatusTransition, 0, len(input.Transitions))
+	seen := make(map[[2]uint]bool)
+	for _, t := range input.Transitions {
+		if !inTeam[t.FromStatusID] || !inTeam[t.ToStatusID] || t.FromStatusID == t.ToStatusID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Transitions must connect two different statuses of this team"})
+			return
+		}
+		key := [2]uint{t.FromStatusID, t.ToStatusID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		transitions = append(transitions, models.StatusTransition{
+			TeamID:       uint(teamID),
+			FromStatusID: t.FromStatusID,
+			ToStatusID:   t.ToStatusID,
+		})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.StatusTransition{}).Error; err != nil {
+			return err
+		}
+		if len(transitions) == 0 {
+			return nil
+		}
+		return tx.Create(&transitions).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save transitions"})
+		return
+	}
+
+	broadcastWorkflow(uint(teamID))
+
+	c.JSON(http.StatusOK, gin.H{"data": transitions})
+}
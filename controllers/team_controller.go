@@ -3,11 +3,14 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"notedteam.backend/config"
 	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
 )
 
 type CreateTeamInput struct {
@@ -53,13 +56,17 @@ func CreateTeam(c *gin.Context) {
 			return err
 		}
 
-		// Langkah B: Tambahkan user (pemilik) sebagai anggota pertama
-		// Gunakan pointer (&user) untuk menambahkan asosiasi
-		if err := tx.Model(&team).Association("Members").Append(&user); err != nil {
+		// Langkah B: Tambahkan user (pemilik) sebagai anggota pertama dengan role owner
+		if err := addTeamMember(tx, team.ID, user.ID, models.RoleOwner); err != nil {
 			// Jika gagal, kembalikan error untuk me-rollback transaksi
 			return err
 		}
 
+		// Langkah C: Buat status alur kerja bawaan (Pending, Working, Completed)
+		if err := models.CreateDefaultStatuses(tx, team.ID); err != nil {
+			return err
+		}
+
 		// Jika semua berhasil, kembalikan nil untuk meng-commit transaksi
 		return nil
 	})
@@ -72,10 +79,17 @@ func CreateTeam(c *gin.Context) {
 
 	// Muat ulang data member agar tampil di response (opsional tapi bagus)
 	config.DB.Preload("Members").First(&team, team.ID)
+	ws.AppHub.SubscribeUser(user.ID, team.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"data": team})
 }
 
+// addTeamMember menambahkan user ke tim dengan role tertentu di dalam transaksi.
+func addTeamMember(tx *gorm.DB, teamID, userID uint, role models.TeamRole) error {
+	member := models.TeamMember{TeamID: teamID, UserID: userID, Role: role}
+	return tx.Create(&member).Error
+}
+
 type InviteInput struct {
 	Email string `json:"email" binding:"required,email"`
 }
@@ -96,21 +110,22 @@ func InviteUserToTeam(c *gin.Context) {
 		return
 	}
 
-	var userToInvite models.User
 	var team models.Team
 
-	// Cari user yang akan diundang berdasarkan email
-	if err := config.DB.Where("email = ?", input.Email).First(&userToInvite).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User to invite not found"})
-		return
-	}
-
 	// Cari tim berdasarkan ID
 	if err := config.DB.First(&team, teamIdUint).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
 		return
 	}
 
+	// Cari user yang akan diundang berdasarkan email.
+	// Jika belum terdaftar, kirim undangan lewat email.
+	var userToInvite models.User
+	if err := config.DB.Where("email = ?", input.Email).First(&userToInvite).Error; err != nil {
+		inviteByEmail(c, team, input.Email)
+		return
+	}
+
 	// Periksa apakah user sudah menjadi anggota
 	var memberCount int64
 	if err := config.DB.
@@ -125,30 +140,73 @@ func InviteUserToTeam(c *gin.Context) {
 		return
 	}
 
-	// Periksa apakah sudah ada undangan yang tertunda
+	// Periksa apakah sudah ada undangan yang tertunda dan masih berlaku
 	var existingInvitation models.Invitation
 	if err := config.DB.
-		Where("user_id = ? AND team_id = ? AND status = ?", userToInvite.ID, teamIdUint, models.InvitationPending).
+		Where("user_id = ? AND team_id = ? AND status = ? AND expires_at > ?", userToInvite.ID, teamIdUint, models.InvitationPending, time.Now()).
 		First(&existingInvitation).Error; err == nil {
 		c.JSON(http.StatusOK, gin.H{"message": "An invitation has already been sent to this user."})
 		return
 	}
 
 	// Buat undangan baru
+	inviterID := c.MustGet("user_id").(uint)
+	expiresAt := time.Now().Add(utils.InvitationTTL)
 	invitation := models.Invitation{
-		UserID: userToInvite.ID,
-		TeamID: uint(teamIdUint),
-		Status: models.InvitationPending,
+		UserID:      &userToInvite.ID,
+		Email:       userToInvite.Email,
+		TeamID:      uint(teamIdUint),
+		InvitedByID: &inviterID,
+		Status:      models.InvitationPending,
+		ExpiresAt:   &expiresAt,
 	}
 
 	if err := config.DB.Create(&invitation).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
 		return
 	}
+	notifyInvitation(invitation, inviterID)
 
 	c.JSON(http.StatusCreated, gin.H{"message": "User successfully invited to the team"})
 }
 
+// inviteByEmail membuat undangan untuk email yang belum terdaftar dan mengirim
+// link undangan bertanda tangan. Undangan akan ditautkan ke akun baru saat
+// pemilik email mendaftar dan memverifikasi akunnya.
+func inviteByEmail(c *gin.Context, team models.Team, email string) {
+	var existingInvitation models.Invitation
+	if err := config.DB.
+		Where("email = ? AND team_id = ? AND user_id IS NULL AND status = ? AND expires_at > ?", email, team.ID, models.InvitationPending, time.Now()).
+		First(&existingInvitation).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "An invitation has already been sent to this email."})
+		return
+	}
+
+	inviter := c.MustGet("user").(models.User)
+	expiresAt := time.Now().Add(utils.InvitationTTL)
+	invitation := models.Invitation{
+		Email:       email,
+		TeamID:      team.ID,
+		InvitedByID: &inviter.ID,
+		Status:      models.InvitationPending,
+		ExpiresAt:   &expiresAt,
+	}
+	if err := config.DB.Create(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	token, err := utils.GenerateInviteToken(invitation.ID, invitation.Email, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation token"})
+		return
+	}
+
+	go utils.SendTeamInvitationEmail(invitation.Email, team.Name, inviter.Name, token)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "The user does not have an account yet. An invitation email has been sent."})
+}
+
 func GetMyTeams(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	var user models.User
@@ -185,41 +243,28 @@ func UpdateTeam(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": team})
 }
 
-// DeleteTeam menghapus sebuah tim beserta semua datanya.
+// DeleteTeam memindahkan tim ke tempat sampah.
 func DeleteTeam(c *gin.Context) {
-	teamID := c.Param("teamId")
-
-	// Middleware sudah memastikan user adalah owner.
-	// Kita akan menghapus tim dan GORM akan menangani relasi (cascade delete jika diatur).
-	// Untuk amannya, kita bisa hapus todos terkait secara manual.
-	tx := config.DB.Begin()
-
-	// 1. Hapus todos di dalam tim
-	if err := tx.Where("team_id = ?", teamID).Delete(&models.Todo{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete todos in team"})
-		return
-	}
-
-	// 2. Hapus asosiasi member (GORM biasanya menangani ini via many2many)
-	// Kita bisa hapus manual untuk memastikan.
-	if err := tx.Exec("DELETE FROM team_members WHERE team_id = ?", teamID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team members"})
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
 		return
 	}
 
-	// 3. Hapus tim itu sendiri
-	if err := tx.Where("id = ?", teamID).Delete(&models.Team{}).Error; err != nil {
-		tx.Rollback()
+	// Middleware sudah memastikan user adalah owner.
+	// Tim hanya dipindahkan ke tempat sampah; anggota, todo, dan data lainnya tetap utuh
+	// sampai tim dipulihkan atau dihapus permanen oleh job pembersihan.
+	if err := config.DB.Delete(&models.Team{}, teamID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team"})
 		return
 	}
 
-	tx.Commit()
+	ws.AppHub.BroadcastToTeam(uint(teamID), "team_deleted", gin.H{"team_id": teamID})
+	ws.AppHub.UnsubscribeTeam(uint(teamID))
 
-	c.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Team moved to trash"})
 }
+
 func GetTeamDetails(c *gin.Context) {
 	teamID := c.Param("teamId")
 
@@ -232,3 +277,221 @@ func GetTeamDetails(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": team})
 }
+
+// teamMemberResponse adalah bentuk data anggota tim beserta role-nya.
+type teamMemberResponse struct {
+	ID    uint            `json:"id"`
+	Name  string          `json:"name"`
+	Email string          `json:"email"`
+	Role  models.TeamRole `json:"role"`
+}
+
+// GetTeamMembers mengambil daftar anggota tim beserta role masing-masing.
+// Rute: GET /api/teams/:teamId/members
+func GetTeamMembers(c *gin.Context) {
+	teamID := c.Param("teamId")
+
+	var members []teamMemberResponse
+	if err := config.DB.Table("team_members").
+		Select("users.id, users.name, users.email, team_members.role").
+		Joins("JOIN users ON users.id = team_members.user_id").
+		Where("team_members.team_id = ?", teamID).
+		Order("users.name asc").
+		Scan(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch team members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+type UpdateMemberRoleInput struct {
+	Role models.TeamRole `json:"role" binding:"required"`
+}
+
+// UpdateMemberRole mengubah role seorang anggota. Aktor hanya boleh mengubah anggota
+// dengan role di bawahnya dan hanya boleh memberi role di bawah role-nya sendiri.
+// Role owner tidak bisa diberikan di sini (gunakan transfer kepemilikan).
+// Rute: PUT /api/teams/:teamId/members/:userId/role
+func UpdateMemberRole(c *gin.Context) {
+	var input UpdateMemberRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !input.Role.IsValid() || input.Role == models.RoleOwner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of: admin, editor, viewer"})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	actorRole := c.MustGet("team_role").(models.TeamRole)
+
+	var member models.TeamMember
+	if err := config.DB.Where("team_id = ? AND user_id = ?", teamID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this team"})
+		return
+	}
+
+	if !actorRole.Outranks(member.Role) || !actorRole.Outranks(input.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot change the role of this member to the requested role"})
+		return
+	}
+
+	if err := config.DB.Model(&member).Update("role", input.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member role"})
+		return
+	}
+	member.Role = input.Role
+
+	ws.AppHub.BroadcastToTeam(uint(teamID), "member_role_updated", member)
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// removeTeamMember mengeluarkan user dari tim di dalam transaksi,
+// termasuk melepas semua penugasan todo miliknya di tim tersebut.
+func removeTeamMember(tx *gorm.DB, teamID, userID uint) error {
+	if err := tx.Exec("DELETE FROM todo_assignees WHERE user_id = ? AND todo_id IN (SELECT id FROM todos WHERE team_id = ?)", userID, teamID).Error; err != nil {
+		return err
+	}
+	return tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error
+}
+
+// announceMemberRemoved memberi tahu tim bahwa seorang anggota keluar/dikeluarkan,
+// lalu memutus koneksi websocket user tersebut untuk tim ini.
+func announceMemberRemoved(teamID, userID uint) {
+	ws.AppHub.BroadcastToTeam(teamID, "member_removed", gin.H{"team_id": teamID, "user_id": userID})
+	ws.AppHub.DisconnectUserFromTeam(userID, teamID)
+}
+
+// RemoveTeamMember mengeluarkan seorang anggota dari tim.
+// Owner tidak bisa dikeluarkan, dan aktor harus memiliki role lebih tinggi dari target.
+// Rute: DELETE /api/teams/:teamId/members/:userId
+func RemoveTeamMember(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	actorID, _ := c.Get("user_id")
+	actorRole := c.MustGet("team_role").(models.TeamRole)
+
+	var member models.TeamMember
+	if err := config.DB.Where("team_id = ? AND user_id = ?", teamID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this team"})
+		return
+	}
+	if member.UserID == actorID.(uint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use the leave endpoint to leave a team"})
+		return
+	}
+	if member.Role == models.RoleOwner || !actorRole.Outranks(member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot remove this member"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return removeTeamMember(tx, member.TeamID, member.UserID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	announceMemberRemoved(member.TeamID, member.UserID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed from team"})
+}
+
+// LeaveTeam mengeluarkan user yang sedang login dari tim.
+// Owner harus menyerahkan kepemilikan terlebih dahulu agar tim tidak kehilangan pemilik.
+// Rute: POST /api/teams/:teamId/leave
+func LeaveTeam(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	if c.MustGet("team_role").(models.TeamRole) == models.RoleOwner {
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner cannot leave the team. Transfer ownership first or delete the team."})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return removeTeamMember(tx, uint(teamID), userID.(uint))
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave team"})
+		return
+	}
+
+	announceMemberRemoved(uint(teamID), userID.(uint))
+
+	c.JSON(http.StatusOK, gin.H{"message": "You have left the team"})
+}
+
+type TransferOwnershipInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// TransferOwnership menyerahkan kepemilikan tim ke anggota lain.
+// Pemilik lama otomatis menjadi admin.
+// Rute: POST /api/teams/:teamId/transfer-ownership
+func TransferOwnership(c *gin.Context) {
+	var input TransferOwnershipInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	ownerID, _ := c.Get("user_id")
+
+	if input.UserID == ownerID.(uint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this team"})
+		return
+	}
+
+	var newOwner models.TeamMember
+	if err := config.DB.Where("team_id = ? AND user_id = ?", teamID, input.UserID).First(&newOwner).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "New owner must be a member of this team"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Team{}).Where("id = ?", teamID).Update("owner_id", input.UserID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, input.UserID).
+			Update("role", models.RoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, ownerID).
+			Update("role", models.RoleAdmin).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(uint(teamID), "ownership_transferred", gin.H{
+		"team_id":      teamID,
+		"old_owner_id": ownerID,
+		"new_owner_id": input.UserID,
+	})
+
+	var team models.Team
+	config.DB.First(&team, teamID)
+	c.JSON(http.StatusOK, gin.H{"data": team})
+}
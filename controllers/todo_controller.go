@@ -1,7 +1,7 @@
 package controllers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,6 +11,7 @@ import (
 	"notedteam.backend/ws" // Impor package WebSocket kita
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // --- Struct untuk Input Data ---
@@ -20,7 +21,18 @@ type CreateTodoInput struct {
 	Title       string             `json:"title" binding:"required"`
 	Description string             `json:"description"`
 	Urgency     models.UrgencyType `json:"urgency"`
+	StatusID    *uint              `json:"status_id"` // Opsional, default kolom pertama tim
 	DueDate     *time.Time         `json:"due_date"`
+	AssigneeIDs []uint             `json:"assignee_ids"` // Opsional, harus anggota tim
+	LabelIDs    []uint             `json:"label_ids"`    // Opsional, harus label milik tim
+	Recurrence  string             `json:"recurrence"`   // Opsional, mis. FREQ=WEEKLY;BYDAY=MO
+
+	AutoCompleteChecklist bool `json:"auto_complete_checklist"`
+}
+
+// AssignTodoInput mendefinisikan user yang akan ditugaskan ke sebuah todo.
+type AssignTodoInput struct {
+	UserID uint `json:"user_id" binding:"required"`
 }
 
 // UpdateTodoInput mendefinisikan data yang bisa diubah pada sebuah todo.
@@ -28,9 +40,51 @@ type CreateTodoInput struct {
 type UpdateTodoInput struct {
 	Title       *string             `json:"title"`
 	Description *string             `json:"description"`
-	Status      *models.StatusType  `json:"status"`
+	Status      *models.StatusType  `json:"status"`    // Status lama, dipetakan ke status tim
+	StatusID    *uint               `json:"status_id"` // Status alur kerja tim
 	Urgency     *models.UrgencyType `json:"urgency"`
 	DueDate     *time.Time          `json:"due_date"`
+	Recurrence  *string             `json:"recurrence"` // String kosong menghentikan pengulangan
+
+	AutoCompleteChecklist *bool `json:"auto_complete_checklist"`
+
+	// Versi todo yang sedang diedit klien; alternatif dari header If-Match
+	Version *uint `json:"version" gorm:"-"`
+}
+
+// --- Fungsi Bantuan ---
+
+// preloadTodo memuat relasi yang selalu disertakan saat mengirim todo ke klien.
+func preloadTodo(db *gorm.DB) *gorm.DB {
+	return db.Preload("Creator").Preload("Editor").Preload("Assignees").Preload("Labels").Preload("TeamStatus")
+}
+
+// findTeamMembers memastikan semua userIDs adalah anggota tim lalu mengembalikan datanya.
+func findTeamMembers(teamID uint, userIDs []uint) ([]models.User, error) {
+	var users []models.User
+	if err := config.DB.
+		Joins("JOIN team_members ON team_members.user_id = users.id AND team_members.team_id = ?", teamID).
+		Where("users.id IN ?", userIDs).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(uniqueIDs(userIDs)) {
+		return nil, errors.New("All assignees must be members of this team")
+	}
+	return users, nil
+}
+
+// uniqueIDs membuang ID duplikat.
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
 }
 
 // --- Fungsi Controller ---
@@ -53,117 +107,180 @@ func CreateTodo(c *gin.Context) {
 	}
 
 	creatorID, _ := c.Get("user_id")
-	// --- PERUBAHAN DI SINI ---
-	urgency := input.Urgency
-	if urgency == "" { // Jika klien tidak mengirim urgensi, gunakan default 'low'
-		urgency = models.UrgencyLow
-	}
-	todo := models.Todo{
-		Title:       input.Title,
-		Description: input.Description,
-		Status:      models.StatusPending,
-		Urgency:     models.UrgencyLow,
-		DueDate:     input.DueDate,
-		TeamID:      uint(teamId),
-		CreatorID:   creatorID.(uint),
-		EditorID:    creatorID.(uint),
-	}
-	config.DB.Preload("Creator").Preload("Editor").First(&todo, todo.ID)
-
-	if err := config.DB.Create(&todo).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create todo"})
+	todo, err := createTodo(uint(teamId), creatorID.(uint), input)
+	if err != nil {
+		respondTodoError(c, err)
 		return
 	}
-
-	// --- Integrasi WebSocket ---
-	msg := ws.Message{Event: "todo_created", Data: todo}
-	jsonMsg, _ := json.Marshal(msg)
-	ws.AppHub.Broadcast <- struct {
-		Message []byte
-		TeamID  uint
-	}{Message: jsonMsg, TeamID: todo.TeamID}
-	// -------------------------
+	setTodoETag(c, todo)
 
 	c.JSON(http.StatusCreated, gin.H{"data": todo})
 }
 
-// GetTeamTodos mengambil semua todo dari sebuah tim.
+// GetTeamTodos mengambil todo dari sebuah tim per halaman.
+// Filter, urutan, dan pagination dijelaskan di buildTodoQuery.
 // Rute: GET /api/teams/:teamId/todos
 func GetTeamTodos(c *gin.Context) {
-	teamID := c.Param("teamId")
-	var todos []models.Todo
+	query, pagination, err := buildTodoQuery(c, c.Param("teamId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := config.DB.Preload("Creator").Preload("Editor").Where("team_id = ?", teamID).Order("created_at desc").Find(&todos).Error; err != nil {
+	todos, nextCursor, err := pagination.fetch(query)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch todos"})
 		return
 	}
+	attachChecklistProgress(todos)
 
-	c.JSON(http.StatusOK, gin.H{"data": todos})
+	c.JSON(http.StatusOK, gin.H{"data": todos, "next_cursor": nextCursor})
 }
 
-// UpdateTodo memperbarui sebuah todo yang spesifik.
+// UpdateTodo memperbarui sebuah todo yang spesifik. Klien wajib mengirim versi todo
+// yang diedit (If-Match atau field version); jika todo sudah diubah orang lain,
+// respons 409 berisi salinan terbaru.
 // Rute: PUT /api/teams/:teamId/todos/:todoId
 func UpdateTodo(c *gin.Context) {
-	teamId := c.Param("teamId")
-	todoId := c.Param("todoId")
+	teamId, _ := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	todoId, err := strconv.ParseUint(c.Param("todoId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
 	editorID, _ := c.Get("user_id")
 	var input UpdateTodoInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	version, ok := expectedTodoVersion(c, input.Version)
+	if !ok {
+		return
+	}
 
-	var todo models.Todo
-	if err := config.DB.Where("id = ? AND team_id = ?", todoId, teamId).First(&todo).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+	todo, err := updateTodo(uint(teamId), uint(todoId), editorID.(uint), version, input)
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, todo.ID)
 		return
 	}
-	todo.EditorID = editorID.(uint)
-	if err := config.DB.Model(&todo).Updates(&input).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update todo"})
+	if err != nil {
+		respondTodoError(c, err)
 		return
 	}
-	config.DB.Preload("Creator").Preload("Editor").First(&todo, todo.ID)
-
-	// --- Integrasi WebSocket ---
-	msg := ws.Message{Event: "todo_updated", Data: todo}
-	jsonMsg, _ := json.Marshal(msg)
-	ws.AppHub.Broadcast <- struct {
-		Message []byte
-		TeamID  uint
-	}{Message: jsonMsg, TeamID: todo.TeamID}
-	// -------------------------
+	setTodoETag(c, todo)
 
 	c.JSON(http.StatusOK, gin.H{"data": todo})
 }
 
-// DeleteTodo menghapus sebuah todo.
+// DeleteTodo memindahkan sebuah todo ke tempat sampah. Data turunannya (komentar,
+// checklist, dll.) tetap disimpan agar todo bisa dipulihkan utuh.
 // Rute: DELETE /api/teams/:teamId/todos/:todoId
 func DeleteTodo(c *gin.Context) {
 	teamIdStr := c.Param("teamId")
 	teamId, _ := strconv.ParseUint(teamIdStr, 10, 32)
-	todoId := c.Param("todoId")
+	todoId, err := strconv.ParseUint(c.Param("todoId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	if err := deleteTodo(uint(teamId), uint(todoId), actorID.(uint)); err != nil {
+		respondTodoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Todo moved to trash"})
+}
 
+// findTeamTodo mencari todo :todoId di dalam tim :teamId.
+func findTeamTodo(c *gin.Context) (models.Todo, bool) {
 	var todo models.Todo
-	if err := config.DB.Where("id = ? AND team_id = ?", todoId, uint(teamId)).First(&todo).Error; err != nil {
+	if err := config.DB.Where("id = ? AND team_id = ?", c.Param("todoId"), c.Param("teamId")).First(&todo).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return todo, false
+	}
+	return todo, true
+}
+
+// AssignTodo menugaskan seorang anggota tim ke sebuah todo.
+// Rute: POST /api/teams/:teamId/todos/:todoId/assignees
+func AssignTodo(c *gin.Context) {
+	var input AssignTodoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, ok := findTeamTodo(c)
+	if !ok {
 		return
 	}
 
-	if err := config.DB.Delete(&todo).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete todo"})
+	assignees, err := findTeamMembers(todo.TeamID, []uint{input.UserID})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	assigneeID := strconv.FormatUint(uint64(input.UserID), 10)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&todo).Association("Assignees").Append(&assignees); err != nil {
+			return err
+		}
+		return recordTodoActivity(tx, todo, actorID.(uint), models.ActivityAssigned, "assignees", nil, &assigneeID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign todo"})
+		return
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
 		return
 	}
 
-	// --- Integrasi WebSocket ---
-	// Kirim hanya ID dari todo yang dihapus
-	msg := ws.Message{Event: "todo_deleted", Data: gin.H{"id": todo.ID}}
-	jsonMsg, _ := json.Marshal(msg)
-	ws.AppHub.Broadcast <- struct {
-		Message []byte
-		TeamID  uint
-	}{Message: jsonMsg, TeamID: uint(teamId)}
-	// -------------------------
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_assigned", gin.H{"todo": todo, "user_id": input.UserID})
+	notifyAssigned(todo, actorID.(uint), input.UserID)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Todo deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": todo})
+}
+
+// UnassignTodo melepas penugasan seorang user dari sebuah todo.
+// Rute: DELETE /api/teams/:teamId/todos/:todoId/assignees/:userId
+func UnassignTodo(c *gin.Context) {
+	todo, ok := findTeamTodo(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	assigneeID := strconv.FormatUint(userID, 10)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&todo).Association("Assignees").Delete(&models.User{ID: uint(userID)}); err != nil {
+			return err
+		}
+		return recordTodoActivity(tx, todo, actorID.(uint), models.ActivityUnassigned, "assignees", &assigneeID, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unassign todo"})
+		return
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_unassigned", gin.H{"todo": todo, "user_id": userID})
+
+	c.JSON(http.StatusOK, gin.H{"data": todo})
 }
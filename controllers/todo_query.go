@@ -0,0 +1,357 @@
+// controllers/todo_query.go
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+const (
+	defaultTodoPageSize = 100
+	maxTodoPageSize     = 200
+)
+
+// noDueDate menggantikan due_date yang kosong saat mengurutkan, sehingga todo tanpa
+// tenggat berada di akhir (asc) atau di awal (desc).
+var noDueDate = time.Date(9000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// todoSort mendefinisikan satu kolom urutan yang didukung GET /todos.
+type todoSort struct {
+	expr  string                             // Ekspresi SQL, ? diisi oleh vars
+	vars  []interface{}                      // Nilai untuk placeholder di expr
+	value func(todo models.Todo) interface{} // Nilai kolom urutan dari todo terakhir di halaman
+	parse func(raw string) (interface{}, error)
+}
+
+func parseCursorTime(raw string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, raw)
+}
+
+func parseCursorInt(raw string) (interface{}, error) {
+	return strconv.Atoi(raw)
+}
+
+var urgencyRanks = map[models.UrgencyType]int{
+	models.UrgencyLow:    1,
+	models.UrgencyMedium: 2,
+	models.UrgencyHigh:   3,
+}
+
+var todoSorts = map[string]todoSort{
+	"created_at": {
+		expr:  "created_at",
+		value: func(todo models.Todo) interface{} { return todo.CreatedAt },
+		parse: parseCursorTime,
+	},
+	"updated_at": {
+		expr:  "updated_at",
+		value: func(todo models.Todo) interface{} { return todo.UpdatedAt },
+		parse: parseCursorTime,
+	},
+	"due_date": {
+		expr: "COALESCE(due_date, ?)",
+		vars: []interface{}{noDueDate},
+		value: func(todo models.Todo) interface{} {
+			if todo.DueDate == nil {
+				return noDueDate
+			}
+			return *todo.DueDate
+		},
+		parse: parseCursorTime,
+	},
+	"rank": {
+		// Urutan kartu di papan kanban
+		expr:  "`rank`",
+		value: func(todo models.Todo) interface{} { return todo.Rank },
+		parse: func(raw string) (interface{}, error) { return raw, nil },
+	},
+	"urgency": {
+		// FIELD() mengembalikan 1..3 sesuai urutan enum: low < medium < high
+		expr:  "FIELD(urgency, 'low', 'medium', 'high')",
+		value: func(todo models.Todo) interface{} { return urgencyRanks[todo.Urgency] },
+		parse: parseCursorInt,
+	},
+}
+
+// todoCursor adalah isi cursor halaman berikutnya. Klien menerimanya sebagai string
+// base64 yang tidak perlu dipahami isinya.
+type todoCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func encodeTodoCursor(cursor todoCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeTodoCursor(s string) (todoCursor, error) {
+	var cursor todoCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errors.New("Invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, errors.New("Invalid cursor")
+	}
+	return cursor, nil
+}
+
+// formatCursorValue mengubah nilai kolom urutan menjadi string untuk disimpan di cursor.
+func formatCursorValue(v interface{}) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case int:
+		return strconv.Itoa(val)
+	case string:
+		return val
+	}
+	return ""
+}
+
+// splitQueryList membaca nilai seperti "pending,working" menjadi slice tanpa elemen kosong.
+func splitQueryList(raw string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// parseIDList membaca nilai seperti "1,2,3" menjadi daftar ID tanpa duplikat.
+func parseIDList(raw string) ([]uint, error) {
+	var ids []uint
+	for _, part := range splitQueryList(raw) {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return uniqueIDs(ids), nil
+}
+
+// parseDateParam menerima tanggal RFC3339 atau YYYY-MM-DD.
+func parseDateParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", raw, time.Local)
+}
+
+// todoPagination menyimpan urutan dan ukuran halaman yang diminta klien.
+type todoPagination struct {
+	sortKey string
+	order   string
+	sort    todoSort
+	limit   int
+}
+
+// buildTodoQuery menerapkan filter, urutan, dan cursor dari query string ke todo milik tim.
+// Error yang dikembalikan selalu berasal dari input klien yang tidak valid.
+//
+// Filter: status, urgency (boleh dipisah koma), creator (ID atau "me"), assignee (ID atau "me"),
+// labels + label_mode, due_from, due_to, dan q (pencarian teks pada judul/deskripsi).
+// Urutan: sort=created_at|updated_at|due_date|urgency|rank dengan order=asc|desc.
+// Pagination: limit (maks 200) dan cursor dari next_cursor halaman sebelumnya.
+func buildTodoQuery(c *gin.Context, teamID string) (*gorm.DB, todoPagination, error) {
+	var pagination todoPagination
+	userID, _ := c.Get("user_id")
+	query := preloadTodo(config.DB).Where("team_id = ?", teamID)
+
+	if statuses := splitQueryList(c.Query("status")); len(statuses) > 0 {
+		for _, status := range statuses {
+			switch models.StatusType(status) {
+			case models.StatusPending, models.StatusWorking, models.StatusCompleted:
+				// Status valid
+			default:
+				return nil, pagination, errors.New("Invalid status filter: " + status)
+			}
+		}
+		query = query.Where("status IN ?", statuses)
+	}
+
+	if raw := c.Query("status_id"); raw != "" {
+		statusIDs, err := parseIDList(raw)
+		if err != nil {
+			return nil, pagination, errors.New("status_id must be a comma-separated list of status IDs")
+		}
+		query = query.Where("status_id IN ?", statusIDs)
+	}
+
+	if urgencies := splitQueryList(c.Query("urgency")); len(urgencies) > 0 {
+		for _, urgency := range urgencies {
+			if _, ok := urgencyRanks[models.UrgencyType(urgency)]; !ok {
+				return nil, pagination, errors.New("Invalid urgency filter: " + urgency)
+			}
+		}
+		query = query.Where("urgency IN ?", urgencies)
+	}
+
+	if creator := c.Query("creator"); creator != "" {
+		if creator == "me" {
+			query = query.Where("creator_id = ?", userID)
+		} else if id, err := strconv.ParseUint(creator, 10, 32); err == nil {
+			query = query.Where("creator_id = ?", id)
+		} else {
+			return nil, pagination, errors.New("creator must be a user ID or 'me'")
+		}
+	}
+
+	// Filter opsional ?assignee=<userId> atau ?assignee=me
+	if assignee := c.Query("assignee"); assignee != "" {
+		if assignee == "me" {
+			assignee = strconv.FormatUint(uint64(userID.(uint)), 10)
+		}
+		query = query.Where("id IN (?)", config.DB.Table("todo_assignees").Select("todo_id").Where("user_id = ?", assignee))
+	}
+
+	// Filter opsional ?labels=1,2&label_mode=any|all
+	// any (default): todo memiliki salah satu label; all: todo memiliki semua label
+	if raw := c.Query("labels"); raw != "" {
+		labelIDs, err := parseLabelFilter(raw)
+		if err != nil {
+			return nil, pagination, err
+		}
+		if len(labelIDs) > 0 {
+			sub := config.DB.Table("todo_labels").Select("todo_id").Where("label_id IN ?", labelIDs)
+			switch c.DefaultQuery("label_mode", "any") {
+			case "any":
+				// Cukup satu label yang cocok
+			case "all":
+				sub = sub.Group("todo_id").Having("COUNT(DISTINCT label_id) = ?", len(labelIDs))
+			default:
+				return nil, pagination, errors.New("label_mode must be 'any' or 'all'")
+			}
+			query = query.Where("id IN (?)", sub)
+		}
+	}
+
+	if raw := c.Query("due_from"); raw != "" {
+		dueFrom, err := parseDateParam(raw)
+		if err != nil {
+			return nil, pagination, errors.New("due_from must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		}
+		query = query.Where("due_date >= ?", dueFrom)
+	}
+	if raw := c.Query("due_to"); raw != "" {
+		dueTo, err := parseDateParam(raw)
+		if err != nil {
+			return nil, pagination, errors.New("due_to must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		}
+		// Tanggal tanpa jam berarti sampai akhir hari tersebut
+		if len(raw) == len("2006-01-02") {
+			dueTo = dueTo.AddDate(0, 0, 1)
+			query = query.Where("due_date < ?", dueTo)
+		} else {
+			query = query.Where("due_date <= ?", dueTo)
+		}
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		like := "%" + escapeLike(q) + "%"
+		query = query.Where("(title LIKE ? OR description LIKE ?)", like, like)
+	}
+
+	// --- Urutan ---
+	sortKey := c.DefaultQuery("sort", "created_at")
+	sort, ok := todoSorts[sortKey]
+	if !ok {
+		return nil, pagination, errors.New("sort must be one of created_at, updated_at, due_date, urgency, rank")
+	}
+	defaultOrder := "desc"
+	if sortKey == "due_date" || sortKey == "rank" {
+		defaultOrder = "asc"
+	}
+	order := strings.ToLower(c.DefaultQuery("order", defaultOrder))
+	if order != "asc" && order != "desc" {
+		return nil, pagination, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	// --- Pagination berbasis cursor (keyset) ---
+	limit := defaultTodoPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return nil, pagination, errors.New("limit must be a positive number")
+		}
+		if n > maxTodoPageSize {
+			n = maxTodoPageSize
+		}
+		limit = n
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeTodoCursor(raw)
+		if err != nil {
+			return nil, pagination, err
+		}
+		if cursor.Sort != sortKey || cursor.Order != order {
+			return nil, pagination, errors.New("cursor does not match the requested sort order")
+		}
+		value, err := sort.parse(cursor.Value)
+		if err != nil {
+			return nil, pagination, errors.New("Invalid cursor")
+		}
+
+		// Baris setelah (value, id) terakhir; id memecah nilai yang sama
+		op := "<"
+		if order == "asc" {
+			op = ">"
+		}
+		vars := append(append([]interface{}{}, sort.vars...), value)
+		vars = append(vars, sort.vars...)
+		vars = append(vars, value, cursor.ID)
+		query = query.Where("("+sort.expr+" "+op+" ? OR ("+sort.expr+" = ? AND id "+op+" ?))", vars...)
+	}
+
+	query = query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                sort.expr + " " + order + ", id " + order,
+		Vars:               sort.vars,
+		WithoutParentheses: true,
+	}})
+
+	return query, todoPagination{sortKey: sortKey, order: order, sort: sort, limit: limit}, nil
+}
+
+// fetch mengambil satu halaman todo dan membuat cursor untuk halaman berikutnya (nil jika sudah habis).
+func (p todoPagination) fetch(query *gorm.DB) ([]models.Todo, *string, error) {
+	var todos []models.Todo
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	if err := query.Limit(p.limit + 1).Find(&todos).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(todos) <= p.limit {
+		return todos, nil, nil
+	}
+
+	todos = todos[:p.limit]
+	last := todos[p.limit-1]
+	next := encodeTodoCursor(todoCursor{
+		Sort:  p.sortKey,
+		Order: p.order,
+		Value: formatCursorValue(p.sort.value(last)),
+		ID:    last.ID,
+	})
+	return todos, &next, nil
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar teks pencarian dicocokkan apa adanya.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,137 @@
+// controllers/todo_recurrence.go
+package controllers
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
+)
+
+// maxSkippedOccurrences membatasi berapa kejadian yang sudah lewat boleh dilompati
+// saat todo berulang baru diselesaikan jauh setelah tenggatnya.
+const maxSkippedOccurrences = 1000
+
+// normalizeRecurrence memvalidasi aturan pengulangan dari klien dan menyimpannya dalam
+// bentuk baku. String kosong berarti todo tidak berulang.
+func normalizeRecurrence(rule string, dueDate *time.Time) (string, error) {
+	if rule == "" {
+		return "", nil
+	}
+	recurrence, err := utils.ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	if dueDate != nil {
+		recurrence = recurrence.Anchor(*dueDate)
+	}
+	return recurrence.String(), nil
+}
+
+// spawnedOccurrence adalah kejadian baru hasil spawnNextOccurrence. Rebalanced bernilai
+// true jika kolom tujuannya diseimbangkan ulang untuk memberi tempat kartu itu.
+type spawnedOccurrence struct {
+	Todo       models.Todo
+	Rebalanced bool
+}
+
+// spawnNextOccurrence membuat kejadian berikutnya dari todo berulang yang baru saja
+// diselesaikan: salinan di kolom pertama dengan tenggat berikutnya, anggota, label,
+// dan checklist yang belum dicentang. Tenggat yang sudah lewat dilompati sampai setelah
+// sekarang. Mengembalikan nil jika todo tidak berulang atau kejadian berikutnya sudah ada.
+func spawnNextOccurrence(tx *gorm.DB, todo models.Todo, actorID uint) (*spawnedOccurrence, error) {
+	if todo.Recurrence == "" || todo.NextOccurrenceID != nil {
+		return nil, nil
+	}
+	recurrence, err := utils.ParseRecurrence(todo.Recurrence)
+	if err != nil {
+		return nil, nil // Aturan lama yang tidak valid diabaikan
+	}
+
+	now := time.Now()
+	base := now
+	if todo.DueDate != nil {
+		base = *todo.DueDate
+	}
+	recurrence = recurrence.Anchor(base)
+	due := recurrence.Next(base)
+	for i := 0; i < maxSkippedOccurrences && !due.After(now); i++ {
+		due = recurrence.Next(due)
+	}
+
+	status, err := resolveTeamStatus(todo.TeamID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	rank, rebalanced, err := rankAtTopOfColumn(tx, todo.TeamID, &status.ID)
+	if err != nil {
+		return nil, err
+	}
+	var source models.Todo
+	if err := tx.Preload("Assignees").Preload("Labels").First(&source, todo.ID).Error; err != nil {
+		return nil, err
+	}
+
+	next := models.Todo{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Status:      legacyStatusOf(status),
+		StatusID:    &status.ID,
+		Rank:        rank,
+		Urgency:     todo.Urgency,
+		DueDate:     &due,
+		TeamID:      todo.TeamID,
+		CreatorID:   todo.CreatorID,
+		EditorID:    actorID,
+		Assignees:   source.Assignees,
+		Labels:      source.Labels,
+		Recurrence:  recurrence.String(),
+
+		AutoCompleteChecklist: todo.AutoCompleteChecklist,
+	}
+	if err := tx.Omit("Assignees.*", "Labels.*").Create(&next).Error; err != nil {
+		return nil, err
+	}
+
+	var items []models.ChecklistItem
+	if err := tx.Where("todo_id = ?", todo.ID).Order("position asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		copies := make([]models.ChecklistItem, len(items))
+		for i, item := range items {
+			copies[i] = models.ChecklistItem{TodoID: next.ID, Title: item.Title, Position: item.Position}
+		}
+		if err := tx.Create(&copies).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordTodoActivity(tx, next, actorID, models.ActivityCreated, "", nil, &next.Title); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Todo{}).Where("id = ?", todo.ID).UpdateColumn("next_occurrence_id", next.ID).Error; err != nil {
+		return nil, err
+	}
+	return &spawnedOccurrence{Todo: next, Rebalanced: rebalanced}, nil
+}
+
+// broadcastNextOccurrence mengirim event todo_created untuk kejadian baru hasil
+// spawnNextOccurrence, didahului column_rebalanced jika kolomnya diseimbangkan ulang.
+// Dipanggil setelah transaksi berhasil.
+func broadcastNextOccurrence(spawned *spawnedOccurrence) {
+	if spawned == nil {
+		return
+	}
+	next := spawned.Todo
+	if spawned.Rebalanced {
+		broadcastColumnRebalanced(next.TeamID, next.StatusID)
+	}
+	preloadTodo(config.DB).First(&next, next.ID)
+	attachTodoChecklistProgress(&next)
+
+	ws.AppHub.BroadcastToTeam(next.TeamID, "todo_created", next)
+}
@@ -0,0 +1,273 @@
+// controllers/todo_service.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// todoError adalah kegagalan dari fungsi layanan todo beserta status HTTP-nya, sehingga
+// handler REST dan perintah websocket memberi jawaban yang sama.
+type todoError struct {
+	Status  int
+	Message string
+}
+
+func (e *todoError) Error() string { return e.Message }
+
+func newTodoError(status int, message string) error {
+	return &todoError{Status: status, Message: message}
+}
+
+// todoErrorStatus mengembalikan status HTTP untuk err; selain todoError dan
+// errVersionConflict dianggap 500.
+func todoErrorStatus(err error) int {
+	var te *todoError
+	if errors.As(err, &te) {
+		return te.Status
+	}
+	if errors.Is(err, errVersionConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// respondTodoError mengirim err dari fungsi layanan todo sebagai respons JSON.
+func respondTodoError(c *gin.Context, err error) {
+	c.JSON(todoErrorStatus(err), gin.H{"error": err.Error()})
+}
+
+// authorizeTeam memastikan user adalah anggota tim aktif dengan izin yang diminta
+// (logika yang sama dengan middleware RequireTeamPermission).
+func authorizeTeam(userID, teamID uint, permission models.Permission) error {
+	var member models.TeamMember
+	if err := config.DB.
+		Joins("JOIN teams ON teams.id = team_members.team_id AND teams.deleted_at IS NULL").
+		Where("team_members.user_id = ? AND team_members.team_id = ?", userID, teamID).
+		First(&member).Error; err != nil {
+		return newTodoError(http.StatusForbidden, "You are not a member of this team")
+	}
+	if !member.Role.Can(permission) {
+		return newTodoError(http.StatusForbidden, "Your role in this team does not allow this action")
+	}
+	return nil
+}
+
+// loadTodo memuat todo beserta relasi dan progres checklist-nya untuk dikirim ke klien.
+func loadTodo(todoID uint) (models.Todo, error) {
+	var todo models.Todo
+	if err := preloadTodo(config.DB).First(&todo, todoID).Error; err != nil {
+		return todo, err
+	}
+	attachTodoChecklistProgress(&todo)
+	return todo, nil
+}
+
+// validUrgency melaporkan apakah urgency salah satu dari low, medium, atau high.
+func validUrgency(urgency models.UrgencyType) bool {
+	switch urgency {
+	case models.UrgencyLow, models.UrgencyMedium, models.UrgencyHigh:
+		return true
+	}
+	return false
+}
+
+// createTodo membuat todo baru di tim teamID atas nama actorID, lalu mengirim event
+// todo_created dan notifikasi penugasan.
+func createTodo(teamID, actorID uint, input CreateTodoInput) (models.Todo, error) {
+	urgency := input.Urgency
+	if urgency == "" { // Jika klien tidak mengirim urgensi, gunakan default 'low'
+		urgency = models.UrgencyLow
+	}
+	if !validUrgency(urgency) {
+		return models.Todo{}, newTodoError(http.StatusBadRequest, "Urgency must be low, medium or high")
+	}
+	status, err := resolveTeamStatus(teamID, input.StatusID, nil)
+	if err != nil {
+		return models.Todo{}, newTodoError(http.StatusBadRequest, err.Error())
+	}
+	recurrence, err := normalizeRecurrence(input.Recurrence, input.DueDate)
+	if err != nil {
+		return models.Todo{}, newTodoError(http.StatusBadRequest, err.Error())
+	}
+
+	todo := models.Todo{
+		Title:       input.Title,
+		Description: input.Description,
+		Status:      legacyStatusOf(status),
+		StatusID:    &status.ID,
+		Urgency:     urgency,
+		DueDate:     input.DueDate,
+		Recurrence:  recurrence,
+		TeamID:      teamID,
+		CreatorID:   actorID,
+		EditorID:    actorID,
+
+		AutoCompleteChecklist: input.AutoCompleteChecklist,
+	}
+	if len(input.AssigneeIDs) > 0 {
+		assignees, err := findTeamMembers(teamID, input.AssigneeIDs)
+		if err != nil {
+			return models.Todo{}, newTodoError(http.StatusBadRequest, err.Error())
+		}
+		todo.Assignees = assignees
+	}
+	if len(input.LabelIDs) > 0 {
+		labels, err := findTeamLabels(teamID, input.LabelIDs)
+		if err != nil {
+			return models.Todo{}, newTodoError(http.StatusBadRequest, err.Error())
+		}
+		todo.Labels = labels
+	}
+
+	rebalanced := false
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if todo.Rank, rebalanced, err = rankAtTopOfColumn(tx, teamID, &status.ID); err != nil {
+			return err
+		}
+		// Omit upsert user/label: cukup buat baris relasi todo_assignees dan todo_labels
+		if err := tx.Omit("Assignees.*", "Labels.*").Create(&todo).Error; err != nil {
+			return err
+		}
+		return recordTodoActivity(tx, todo, todo.CreatorID, models.ActivityCreated, "", nil, &todo.Title)
+	})
+	if err != nil {
+		return models.Todo{}, newTodoError(http.StatusInternalServerError, "Failed to create todo")
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		return models.Todo{}, newTodoError(http.StatusNotFound, "Todo not found in this team")
+	}
+
+	if rebalanced {
+		broadcastColumnRebalanced(todo.TeamID, todo.StatusID)
+	}
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_created", todo)
+
+	assigneeIDs := make([]uint, len(todo.Assignees))
+	for i, assignee := range todo.Assignees {
+		assigneeIDs[i] = assignee.ID
+	}
+	notifyAssigned(todo, todo.CreatorID, assigneeIDs...)
+
+	return todo, nil
+}
+
+// updateTodo mengubah todo todoID di tim teamID jika versinya masih version. Jika todo
+// sudah diubah orang lain, mengembalikan errVersionConflict.
+func updateTodo(teamID, todoID, actorID, version uint, input UpdateTodoInput) (models.Todo, error) {
+	var todo models.Todo
+	if err := config.DB.Where("id = ? AND team_id = ?", todoID, teamID).First(&todo).Error; err != nil {
+		return todo, newTodoError(http.StatusNotFound, "Todo not found in this team")
+	}
+	if todo.Version != version {
+		return todo, errVersionConflict
+	}
+
+	if input.Urgency != nil && !validUrgency(*input.Urgency) {
+		return todo, newTodoError(http.StatusBadRequest, "Urgency must be low, medium or high")
+	}
+
+	// Perpindahan status divalidasi terhadap status dan transisi milik tim
+	if input.StatusID != nil || input.Status != nil {
+		status, err := resolveTeamStatus(todo.TeamID, input.StatusID, input.Status)
+		if err != nil {
+			return todo, newTodoError(http.StatusBadRequest, err.Error())
+		}
+		if !canTransition(todo.TeamID, todo.StatusID, status.ID) {
+			return todo, newTodoError(http.StatusUnprocessableEntity, errTransitionNotAllowed.Error())
+		}
+		legacy := legacyStatusOf(status)
+		input.StatusID = &status.ID
+		input.Status = &legacy
+	}
+	if input.Recurrence != nil {
+		dueDate := todo.DueDate
+		if input.DueDate != nil {
+			dueDate = input.DueDate
+		}
+		recurrence, err := normalizeRecurrence(*input.Recurrence, dueDate)
+		if err != nil {
+			return todo, newTodoError(http.StatusBadRequest, err.Error())
+		}
+		input.Recurrence = &recurrence
+	}
+
+	oldValues := todoFieldValues(todo)
+	wasCompleted := todo.Status == models.StatusCompleted
+	var nextOccurrence *spawnedOccurrence
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := claimTodoVersion(tx, todo.ID, version); err != nil {
+			return err
+		}
+		// Updates(&input) tidak menyentuh editor_id, jadi ditulis terpisah
+		if err := tx.Model(&models.Todo{}).Where("id = ?", todo.ID).UpdateColumn("editor_id", actorID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&todo).Updates(&input).Error; err != nil {
+			return err
+		}
+		var updated models.Todo
+		if err := tx.First(&updated, todo.ID).Error; err != nil {
+			return err
+		}
+		if err := recordTodoChanges(tx, oldValues, updated, actorID); err != nil {
+			return err
+		}
+
+		// Todo berulang yang baru diselesaikan memunculkan kejadian berikutnya
+		if !wasCompleted && updated.Status == models.StatusCompleted {
+			next, err := spawnNextOccurrence(tx, updated, actorID)
+			if err != nil {
+				return err
+			}
+			nextOccurrence = next
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		return todo, err
+	}
+	if err != nil {
+		return todo, newTodoError(http.StatusInternalServerError, "Failed to update todo")
+	}
+	todo, err = loadTodo(todo.ID)
+	if err != nil {
+		return todo, newTodoError(http.StatusNotFound, "Todo not found in this team")
+	}
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_updated", todo)
+	broadcastNextOccurrence(nextOccurrence)
+
+	return todo, nil
+}
+
+// deleteTodo memindahkan todo todoID di tim teamID ke tempat sampah. Data turunannya
+// (komentar, checklist, dll.) tetap disimpan agar todo bisa dipulihkan utuh.
+func deleteTodo(teamID, todoID, actorID uint) error {
+	var todo models.Todo
+	if err := config.DB.Where("id = ? AND team_id = ?", todoID, teamID).First(&todo).Error; err != nil {
+		return newTodoError(http.StatusNotFound, "Todo not found in this team")
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&todo).Error; err != nil {
+			return err
+		}
+		// Judul dicatat agar feed tim tetap bisa menampilkannya setelah todo dihapus permanen
+		return recordTodoActivity(tx, todo, actorID, models.ActivityDeleted, "", &todo.Title, nil)
+	})
+	if err != nil {
+		return newTodoError(http.StatusInternalServerError, "Failed to delete todo")
+	}
+
+	// Kirim hanya ID dari todo yang dihapus
+	ws.AppHub.BroadcastToTeam(teamID, "todo_deleted", gin.H{"id": todo.ID})
+	return nil
+}
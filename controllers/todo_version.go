@@ -0,0 +1,84 @@
+// controllers/todo_version.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/models"
+)
+
+var errVersionConflict = errors.New("todo was changed by someone else")
+
+// versionConflictMessage adalah pesan untuk klien saat versi todo bentrok.
+const versionConflictMessage = "This todo was changed by someone else"
+
+// todoETag mengubah versi todo menjadi nilai header ETag, mis. "3".
+func todoETag(todo models.Todo) string {
+	return `"` + strconv.FormatUint(uint64(todo.Version), 10) + `"`
+}
+
+// setTodoETag mengirim versi todo saat ini sebagai header ETag.
+func setTodoETag(c *gin.Context, todo models.Todo) {
+	c.Header("ETag", todoETag(todo))
+}
+
+// expectedTodoVersion membaca versi yang diketahui klien dari header If-Match atau
+// field version di body. Salah satunya wajib ada; jika keduanya ada harus sama.
+func expectedTodoVersion(c *gin.Context, bodyVersion *uint) (uint, bool) {
+	var headerVersion *uint
+	if raw := strings.TrimSpace(c.GetHeader("If-Match")); raw != "" {
+		raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+		n, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must be the todo's ETag"})
+			return 0, false
+		}
+		version := uint(n)
+		headerVersion = &version
+	}
+
+	switch {
+	case headerVersion == nil && bodyVersion == nil:
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "Send the todo's version in the If-Match header or the version field"})
+		return 0, false
+	case headerVersion != nil && bodyVersion != nil && *headerVersion != *bodyVersion:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match and version do not match"})
+		return 0, false
+	case headerVersion != nil:
+		return *headerVersion, true
+	}
+	return *bodyVersion, true
+}
+
+// claimTodoVersion menaikkan versi todo hanya jika versinya masih sama dengan yang
+// diketahui klien. Dijalankan di awal transaksi agar baris terkunci sampai commit.
+func claimTodoVersion(tx *gorm.DB, todoID, version uint) error {
+	result := tx.Model(&models.Todo{}).
+		Where("id = ? AND version = ?", todoID, version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errVersionConflict
+	}
+	return nil
+}
+
+// respondVersionConflict mengirim 409 beserta salinan todo terbaru di server agar
+// klien bisa menampilkan perbandingan dan menggabungkan perubahan.
+func respondVersionConflict(c *gin.Context, todoID uint) {
+	current, err := loadTodo(todoID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team"})
+		return
+	}
+
+	setTodoETag(c, current)
+	c.JSON(http.StatusConflict, gin.H{"error": versionConflictMessage, "data": current})
+}
@@ -0,0 +1,188 @@
+// controllers/trash_controller.go
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// trashPurgeAt menghitung kapan item di tempat sampah akan dihapus permanen.
+func trashPurgeAt(deletedAt gorm.DeletedAt) time.Time {
+	return deletedAt.Time.Add(config.TrashRetention())
+}
+
+// findTrashedTodo mencari todo :todoId di tempat sampah tim :teamId.
+func findTrashedTodo(c *gin.Context) (models.Todo, bool) {
+	var todo models.Todo
+	err := config.DB.Unscoped().
+		Where("id = ? AND team_id = ? AND deleted_at IS NOT NULL", c.Param("todoId"), c.Param("teamId")).
+		First(&todo).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found in this team's trash"})
+		return todo, false
+	}
+	return todo, true
+}
+
+// GetTeamTrash mengambil todo tim yang ada di tempat sampah, terbaru dihapus lebih dulu.
+// Rute: GET /api/teams/:teamId/trash
+func GetTeamTrash(c *gin.Context) {
+	var todos []models.Todo
+	err := preloadTodo(config.DB.Unscoped()).
+		Where("team_id = ? AND deleted_at IS NOT NULL", c.Param("teamId")).
+		Order("deleted_at desc").
+		Find(&todos).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch trash"})
+		return
+	}
+
+	type trashedTodo struct {
+		models.Todo
+		PurgeAt time.Time `json:"purge_at"`
+	}
+	data := make([]trashedTodo, len(todos))
+	for i, todo := range todos {
+		data[i] = trashedTodo{Todo: todo, PurgeAt: trashPurgeAt(todo.DeletedAt)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// RestoreTodo mengembalikan todo dari tempat sampah ke papan, di posisi semula.
+// Rute: POST /api/teams/:teamId/trash/:todoId/restore
+func RestoreTodo(c *gin.Context) {
+	todo, ok := findTrashedTodo(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&todo).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return recordTodoActivity(tx, todo, actorID.(uint), models.ActivityRestored, "", nil, &todo.Title)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore todo"})
+		return
+	}
+	preloadTodo(config.DB).First(&todo, todo.ID)
+	attachTodoChecklistProgress(&todo)
+
+	ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_restored", todo)
+
+	c.JSON(http.StatusOK, gin.H{"data": todo})
+}
+
+// PurgeTrashedTodo menghapus permanen todo yang ada di tempat sampah beserta
+// komentar, checklist, dan relasinya. Riwayat aktivitas tetap disimpan.
+// Rute: DELETE /api/teams/:teamId/trash/:todoId
+func PurgeTrashedTodo(c *gin.Context) {
+	todo, ok := findTrashedTodo(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return models.PurgeTodos(tx, []uint{todo.ID})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete todo permanently"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Todo deleted permanently"})
+}
+
+// findOwnedTrashedTeam mencari tim :teamId di tempat sampah yang dimiliki user yang login.
+func findOwnedTrashedTeam(c *gin.Context) (models.Team, bool) {
+	userID, _ := c.Get("user_id")
+
+	var team models.Team
+	err := config.DB.Unscoped().
+		Where("id = ? AND owner_id = ? AND deleted_at IS NOT NULL", c.Param("teamId"), userID).
+		First(&team).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found in your trash"})
+		return team, false
+	}
+	return team, true
+}
+
+// GetMyDeletedTeams mengambil tim milik user yang ada di tempat sampah.
+// Rute: GET /api/trash/teams
+func GetMyDeletedTeams(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var teams []models.Team
+	err := config.DB.Unscoped().
+		Where("owner_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&teams).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch trash"})
+		return
+	}
+
+	type trashedTeam struct {
+		models.Team
+		PurgeAt time.Time `json:"purge_at"`
+	}
+	data := make([]trashedTeam, len(teams))
+	for i, team := range teams {
+		data[i] = trashedTeam{Team: team, PurgeAt: trashPurgeAt(team.DeletedAt)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// RestoreTeam mengembalikan tim dari tempat sampah. Hanya owner yang bisa memulihkan.
+// Rute: POST /api/trash/teams/:teamId/restore
+func RestoreTeam(c *gin.Context) {
+	team, ok := findOwnedTrashedTeam(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Unscoped().Model(&team).UpdateColumn("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore team"})
+		return
+	}
+	team.DeletedAt = gorm.DeletedAt{}
+
+	// Koneksi /ws/me milik anggota kembali mengikuti tim ini
+	var memberIDs []uint
+	config.DB.Model(&models.TeamMember{}).Where("team_id = ?", team.ID).Pluck("user_id", &memberIDs)
+	for _, memberID := range memberIDs {
+		ws.AppHub.SubscribeUser(memberID, team.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": team})
+}
+
+// PurgeDeletedTeam menghapus permanen tim yang ada di tempat sampah beserta semua datanya.
+// Rute: DELETE /api/trash/teams/:teamId
+func PurgeDeletedTeam(c *gin.Context) {
+	team, ok := findOwnedTrashedTeam(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return models.PurgeTeam(tx, team.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team permanently"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted permanently"})
+}
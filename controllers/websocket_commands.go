@@ -0,0 +1,242 @@
+// controllers/websocket_commands.go
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/ws"
+)
+
+// ClientMessage adalah perintah yang dikirim klien lewat koneksi websocket, mis.
+// {"id": "1", "type": "create_todo", "team_id": 3, "data": {...}}. ID bebas dipilih
+// klien dan dikembalikan apa adanya di balasan "ack" atau "error".
+type ClientMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Type   string          `json:"type"`
+	TeamID uint            `json:"team_id"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// commandReply adalah isi event "ack" atau "error" untuk satu ClientMessage.
+// Status memakai kode HTTP yang sama dengan endpoint REST-nya.
+type commandReply struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Type   string          `json:"type"`
+	Status int             `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Data   interface{}     `json:"data,omitempty"`
+}
+
+// commandHandler menjalankan satu jenis perintah dan mengembalikan data untuk balasannya.
+// Data tetap dikirim bersama error, mis. salinan todo terbaru saat versi bentrok.
+type commandHandler func(client *ws.Client, msg ClientMessage) (interface{}, error)
+
+// clientCommands memetakan field type ke handler perintahnya.
+var clientCommands = map[string]commandHandler{
+	"ping":        pingCommand,
+	"subscribe":   subscribeCommand,
+	"unsubscribe": unsubscribeCommand,
+	"create_todo": createTodoCommand,
+	"update_todo": updateTodoCommand,
+	"delete_todo": deleteTodoCommand,
+
+	"start_editing": startEditingCommand,
+	"stop_editing":  stopEditingCommand,
+}
+
+// handleClientMessage menjalankan satu perintah dari klien lalu membalas dengan event
+// "ack" atau "error". Pada koneksi per tim, team_id boleh dikosongkan.
+func handleClientMessage(client *ws.Client, raw []byte) {
+	var msg ClientMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		replyCommand(client, msg, nil, newTodoError(http.StatusBadRequest, "Invalid message"))
+		return
+	}
+	if msg.TeamID == 0 && !client.Personal {
+		if teams := ws.AppHub.Teams(client); len(teams) == 1 {
+			msg.TeamID = teams[0]
+		}
+	}
+
+	handler, ok := clientCommands[msg.Type]
+	if !ok {
+		replyCommand(client, msg, nil, newTodoError(http.StatusBadRequest, "Unknown message type"))
+		return
+	}
+	data, err := handler(client, msg)
+	replyCommand(client, msg, data, err)
+}
+
+// replyCommand mengirim balasan "ack", atau "error" jika err tidak nil.
+func replyCommand(client *ws.Client, msg ClientMessage, data interface{}, err error) {
+	reply := commandReply{ID: msg.ID, Type: msg.Type, Data: data}
+	if err != nil {
+		reply.Status = todoErrorStatus(err)
+		reply.Error = err.Error()
+		ws.AppHub.SendToClient(client, "error", reply)
+		return
+	}
+	ws.AppHub.SendToClient(client, "ack", reply)
+}
+
+// decodeCommand membaca field data ke v lalu memvalidasinya dengan tag binding yang
+// sama seperti ShouldBindJSON di handler REST.
+func decodeCommand(msg ClientMessage, v interface{}) error {
+	raw := msg.Data
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return newTodoError(http.StatusBadRequest, err.Error())
+	}
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+		return newTodoError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// authorizeCommand memastikan perintah menyebut team_id dan pengirimnya punya izin di tim itu.
+func authorizeCommand(client *ws.Client, msg ClientMessage, permission models.Permission) error {
+	if msg.TeamID == 0 {
+		return newTodoError(http.StatusBadRequest, "team_id is required")
+	}
+	return authorizeTeam(client.UserID, msg.TeamID, permission)
+}
+
+// todoCommandTarget adalah data perintah yang menunjuk satu todo.
+type todoCommandTarget struct {
+	TodoID uint `json:"todo_id" binding:"required"`
+}
+
+// pingCommand membalas dengan waktu server, untuk menjaga koneksi tetap hidup.
+func pingCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	return gin.H{"time": time.Now()}, nil
+}
+
+// subscribeCommand membuat koneksi /ws/me ikut menerima event tim team_id.
+func subscribeCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if !client.Personal {
+		return nil, newTodoError(http.StatusBadRequest, "Subscriptions are only supported on /api/ws/me")
+	}
+	if err := authorizeCommand(client, msg, models.PermViewTeam); err != nil {
+		return nil, err
+	}
+	ws.AppHub.Subscribe(client, msg.TeamID)
+	return gin.H{"team_id": msg.TeamID}, nil
+}
+
+// unsubscribeCommand menghentikan event tim team_id pada koneksi /ws/me.
+func unsubscribeCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if !client.Personal {
+		return nil, newTodoError(http.StatusBadRequest, "Subscriptions are only supported on /api/ws/me")
+	}
+	ws.AppHub.Unsubscribe(client, msg.TeamID)
+	return gin.H{"team_id": msg.TeamID}, nil
+}
+
+// createTodoCommand sama dengan POST /api/teams/:teamId/todos; data berisi CreateTodoInput.
+func createTodoCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if err := authorizeCommand(client, msg, models.PermManageTodos); err != nil {
+		return nil, err
+	}
+	var input CreateTodoInput
+	if err := decodeCommand(msg, &input); err != nil {
+		return nil, err
+	}
+	todo, err := createTodo(msg.TeamID, client.UserID, input)
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// updateTodoCommand sama dengan PUT /api/teams/:teamId/todos/:todoId; data berisi todo_id,
+// version (wajib), dan field UpdateTodoInput. Jika versi bentrok, balasan error membawa
+// salinan todo terbaru.
+func updateTodoCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if err := authorizeCommand(client, msg, models.PermManageTodos); err != nil {
+		return nil, err
+	}
+	var target todoCommandTarget
+	if err := decodeCommand(msg, &target); err != nil {
+		return nil, err
+	}
+	var input UpdateTodoInput
+	if err := decodeCommand(msg, &input); err != nil {
+		return nil, err
+	}
+	if input.Version == nil {
+		return nil, newTodoError(http.StatusPreconditionRequired, "Send the todo's version in the version field")
+	}
+
+	todo, err := updateTodo(msg.TeamID, target.TodoID, client.UserID, *input.Version, input)
+	if errors.Is(err, errVersionConflict) {
+		current, loadErr := loadTodo(todo.ID)
+		if loadErr != nil {
+			return nil, newTodoError(http.StatusNotFound, "Todo not found in this team")
+		}
+		return current, newTodoError(http.StatusConflict, versionConflictMessage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// deleteTodoCommand sama dengan DELETE /api/teams/:teamId/todos/:todoId; data berisi todo_id.
+func deleteTodoCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if err := authorizeCommand(client, msg, models.PermManageTodos); err != nil {
+		return nil, err
+	}
+	var target todoCommandTarget
+	if err := decodeCommand(msg, &target); err != nil {
+		return nil, err
+	}
+	if err := deleteTodo(msg.TeamID, target.TodoID, client.UserID); err != nil {
+		return nil, err
+	}
+	return gin.H{"id": target.TodoID}, nil
+}
+
+// startEditingCommand menandai pengirim sedang mengedit todo_id selama ws.EditingTTL.
+// Klien mengirim ulang perintah ini sebelum kedaluwarsa selama form edit masih terbuka.
+func startEditingCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if err := authorizeCommand(client, msg, models.PermManageTodos); err != nil {
+		return nil, err
+	}
+	var target todoCommandTarget
+	if err := decodeCommand(msg, &target); err != nil {
+		return nil, err
+	}
+	var count int64
+	config.DB.Model(&models.Todo{}).Where("id = ? AND team_id = ?", target.TodoID, msg.TeamID).Count(&count)
+	if count == 0 {
+		return nil, newTodoError(http.StatusNotFound, "Todo not found in this team")
+	}
+
+	editing, ok := ws.AppHub.StartEditing(client, msg.TeamID, target.TodoID)
+	if !ok {
+		return nil, newTodoError(http.StatusBadRequest, "This connection does not follow this team")
+	}
+	return editing, nil
+}
+
+// stopEditingCommand menghapus tanda mengedit pengirim pada todo_id.
+func stopEditingCommand(client *ws.Client, msg ClientMessage) (interface{}, error) {
+	if msg.TeamID == 0 {
+		return nil, newTodoError(http.StatusBadRequest, "team_id is required")
+	}
+	var target todoCommandTarget
+	if err := decodeCommand(msg, &target); err != nil {
+		return nil, err
+	}
+	ws.AppHub.StopEditing(msg.TeamID, target.TodoID, client.UserID)
+	return gin.H{"todo_id": target.TodoID}, nil
+}
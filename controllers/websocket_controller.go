@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"notedteam.backend/config"
+	"notedteam.backend/models"
 	"notedteam.backend/ws"
 )
 
@@ -19,6 +20,16 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
+// activeTeamIDs mengambil ID semua tim aktif yang diikuti user.
+func activeTeamIDs(userID uint) ([]uint, error) {
+	var teamIDs []uint
+	err := config.DB.Table("team_members").
+		Joins("JOIN teams ON teams.id = team_members.team_id AND teams.deleted_at IS NULL").
+		Where("team_members.user_id = ?", userID).
+		Pluck("team_members.team_id", &teamIDs).Error
+	return teamIDs, err
+}
+
 // ServeWs menangani permintaan koneksi websocket.
 // Rute: GET /ws/teams/:teamId?token=...
 func ServeWs(c *gin.Context) {
@@ -33,10 +44,9 @@ func ServeWs(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah user adalah member dari tim ini (logika dari TeamMemberMiddleware)
-	var memberCount int64
-	if err := config.DB.Table("team_members").Where("user_id = ? AND team_id = ?", userID, uint(teamId)).Count(&memberCount).Error; err != nil || memberCount == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this team"})
+	// Cek apakah user adalah member dari tim ini
+	if err := authorizeTeam(userID.(uint), uint(teamId), models.PermViewTeam); err != nil {
+		respondTodoError(c, err)
 		return
 	}
 
@@ -48,11 +58,8 @@ func ServeWs(c *gin.Context) {
 	}
 
 	// 3. Buat objek Client dan daftarkan ke Hub
-	client := &ws.Client{
-		Conn:   conn,
-		Send:   make(chan []byte, 256),
-		TeamID: uint(teamId),
-	}
+	sessionID, _ := c.Get("session_id")
+	client := ws.NewClient(conn, userID.(uint), sessionID.(uint), false, uint(teamId))
 	ws.AppHub.Register <- client
 
 	// 4. Jalankan goroutine untuk membaca dan menulis pesan
@@ -60,20 +67,49 @@ func ServeWs(c *gin.Context) {
 	go readPump(client)
 }
 
+// ServeUserWs membuka satu koneksi websocket untuk semua tim user sekaligus, ditambah
+// event pribadi seperti notifikasi. Tim yang diikuti bisa diatur dengan perintah
+// subscribe/unsubscribe (lihat websocket_commands.go) dan diperbarui otomatis saat
+// user bergabung atau keluar dari tim.
+// Rute: GET /api/ws/me?token=...
+func ServeUserWs(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	teamIDs, err := activeTeamIDs(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch your teams"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println("Failed to upgrade connection:", err)
+		return
+	}
+
+	sessionID, _ := c.Get("session_id")
+	client := ws.NewClient(conn, userID.(uint), sessionID.(uint), true, teamIDs...)
+	ws.AppHub.Register <- client
+	ws.AppHub.SendToClient(client, "subscriptions", gin.H{"team_ids": teamIDs})
+
+	go writePump(client)
+	go readPump(client)
+}
+
 func readPump(client *ws.Client) {
 	defer func() {
 		ws.AppHub.Unregister <- client
 		client.Conn.Close()
 	}()
 	for {
-		// Baca pesan dari koneksi (kita tidak mengharapkan pesan dari client untuk saat ini)
-		_, _, err := client.Conn.ReadMessage()
+		_, message, err := client.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("error: %v", err)
 			}
 			break
 		}
+		handleClientMessage(client, message)
 	}
 }
 
@@ -0,0 +1,138 @@
+// jobs/due_reminders.go
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+	"notedteam.backend/ws"
+)
+
+// overdueWindow membatasi pengingat overdue ke todo yang belum lama lewat tenggat,
+// agar todo lama tidak membanjiri email saat fitur ini pertama kali dijalankan.
+const overdueWindow = 7 * 24 * time.Hour
+
+// RunDueReminders secara berkala mengirim pengingat untuk todo yang segera jatuh tempo
+// (dalam REMINDER_LEAD_HOURS) atau sudah lewat tenggat. Jalankan sebagai goroutine.
+func RunDueReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sendDueReminders()
+		<-ticker.C
+	}
+}
+
+func sendDueReminders() {
+	now := time.Now()
+	sent := remindTodos(models.ReminderDueSoon, now, "todos.due_date > ? AND todos.due_date <= ?", now, now.Add(config.ReminderLeadTime()))
+	sent += remindTodos(models.ReminderOverdue, now, "todos.due_date <= ? AND todos.due_date > ?", now, now.Add(-overdueWindow))
+	if sent > 0 {
+		log.Printf("Due reminders: sent %d reminder(s)", sent)
+	}
+}
+
+// remindTodos mengirim pengingat kind untuk todo belum selesai yang cocok dengan kondisi
+// tenggat, lalu mengembalikan jumlah todo yang diingatkan.
+func remindTodos(kind string, now time.Time, dueCondition string, args ...interface{}) int {
+	var todos []models.Todo
+	err := config.DB.Preload("Assignees").Preload("Creator").
+		Joins("JOIN teams ON teams.id = todos.team_id AND teams.deleted_at IS NULL").
+		Where(dueCondition, args...).
+		Where("todos.status <> ?", models.StatusCompleted).
+		Where("NOT EXISTS (SELECT 1 FROM todo_reminders WHERE todo_reminders.todo_id = todos.id AND todo_reminders.kind = ? AND todo_reminders.due_date = todos.due_date)", kind).
+		Find(&todos).Error
+	if err != nil {
+		log.Printf("Due reminders failed: %v", err)
+		return 0
+	}
+	if len(todos) == 0 {
+		return 0
+	}
+
+	teamIDs := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		teamIDs = append(teamIDs, todo.TeamID)
+	}
+	var teams []models.Team
+	config.DB.Where("id IN ?", teamIDs).Find(&teams)
+	teamNames := make(map[uint]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	sent := 0
+	for _, todo := range todos {
+		// Catat dulu sebelum mengirim; jika baris sudah ada (proses lain lebih dulu), lewati
+		reminder := models.TodoReminder{
+			TodoID:  todo.ID,
+			Kind:    kind,
+			DueDate: *todo.DueDate,
+			SentAt:  now,
+		}
+		result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminder)
+		if result.Error != nil {
+			log.Printf("Due reminders: failed to record reminder for todo %d: %v", todo.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		// Pengingat untuk anggota yang ditugaskan, atau pembuatnya jika belum ada
+		recipients := todo.Assignees
+		if len(recipients) == 0 && todo.Creator.ID != 0 {
+			recipients = []models.User{todo.Creator}
+		}
+		notificationType := models.NotificationDueSoon
+		if kind == models.ReminderOverdue {
+			notificationType = models.NotificationOverdue
+		}
+		userIDs := make([]uint, 0, len(recipients))
+		notifications := make([]models.Notification, 0, len(recipients))
+		delivered := 0
+		for _, user := range recipients {
+			userIDs = append(userIDs, user.ID)
+			notifications = append(notifications, models.Notification{
+				UserID: user.ID,
+				Type:   notificationType,
+				TeamID: &todo.TeamID,
+				TodoID: &todo.ID,
+			})
+			if err := utils.SendDueReminderEmail(user.Email, todo.Title, teamNames[todo.TeamID], *todo.DueDate, kind == models.ReminderOverdue); err != nil {
+				log.Printf("Due reminders: failed to email user %d about todo %d: %v", user.ID, todo.ID, err)
+				continue
+			}
+			delivered++
+		}
+
+		// Jika tidak ada email yang terkirim, hapus catatannya agar dicoba lagi di putaran berikutnya
+		if len(recipients) > 0 && delivered == 0 {
+			if err := config.DB.Delete(&reminder).Error; err != nil {
+				log.Printf("Due reminders: failed to release reminder for todo %d: %v", todo.ID, err)
+			}
+			continue
+		}
+
+		ws.AppHub.BroadcastToTeam(todo.TeamID, "todo_reminder", map[string]interface{}{
+			"kind":     kind,
+			"todo":     todo,
+			"user_ids": userIDs,
+		})
+
+		created, err := models.CreateNotifications(config.DB, notifications)
+		if err != nil {
+			log.Printf("Due reminders: failed to create notifications for todo %d: %v", todo.ID, err)
+		}
+		for _, n := range created {
+			ws.AppHub.SendToUser(n.UserID, "notification", n)
+		}
+		sent++
+	}
+	return sent
+}
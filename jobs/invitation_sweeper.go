@@ -0,0 +1,35 @@
+// jobs/invitation_sweeper.go
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+// RunInvitationSweeper secara berkala menandai undangan tertunda yang sudah
+// melewati masa berlakunya sebagai 'expired'. Jalankan sebagai goroutine.
+func RunInvitationSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sweepExpiredInvitations()
+		<-ticker.C
+	}
+}
+
+func sweepExpiredInvitations() {
+	result := config.DB.Model(&models.Invitation{}).
+		Where("status = ? AND expires_at < ?", models.InvitationPending, time.Now()).
+		Update("status", models.InvitationExpired)
+	if result.Error != nil {
+		log.Printf("Invitation sweeper failed: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Invitation sweeper: marked %d invitation(s) as expired", result.RowsAffected)
+	}
+}
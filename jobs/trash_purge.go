@@ -0,0 +1,65 @@
+// jobs/trash_purge.go
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+// RunTrashPurge secara berkala menghapus permanen todo dan tim yang sudah berada
+// di tempat sampah lebih lama dari TRASH_RETENTION_DAYS. Jalankan sebagai goroutine.
+func RunTrashPurge(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeTrash()
+		<-ticker.C
+	}
+}
+
+func purgeTrash() {
+	cutoff := time.Now().Add(-config.TrashRetention())
+
+	// Tim lebih dulu, karena PurgeTeam juga menghapus semua todo di dalamnya
+	var teamIDs []uint
+	if err := config.DB.Unscoped().Model(&models.Team{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &teamIDs).Error; err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+	for _, teamID := range teamIDs {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return models.PurgeTeam(tx, teamID)
+		})
+		if err != nil {
+			log.Printf("Trash purge: failed to purge team %d: %v", teamID, err)
+		}
+	}
+
+	var todoIDs []uint
+	if err := config.DB.Unscoped().Model(&models.Todo{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &todoIDs).Error; err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+	if len(todoIDs) > 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return models.PurgeTodos(tx, todoIDs)
+		})
+		if err != nil {
+			log.Printf("Trash purge: failed to purge todos: %v", err)
+			return
+		}
+	}
+
+	if len(teamIDs) > 0 || len(todoIDs) > 0 {
+		log.Printf("Trash purge: removed %d team(s) and %d todo(s)", len(teamIDs), len(todoIDs))
+	}
+}
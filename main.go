@@ -3,9 +3,11 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"notedteam.backend/config"
 	"notedteam.backend/controllers"
+	"notedteam.backend/jobs"
 	"notedteam.backend/middlewares"
 	"notedteam.backend/models"
 	"notedteam.backend/ws"
@@ -19,14 +21,17 @@ func main() {
 	config.ConnectDatabase()
 
 	log.Println("Running database migrations...")
-	err := config.DB.AutoMigrate(&models.User{}, &models.Team{}, &models.Todo{}, &models.Invitation{})
-	if err != nil {
+	if err := config.MigrateDatabase(); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
 	go ws.AppHub.Run()
 	log.Println("WebSocket Hub started.")
 
+	go jobs.RunInvitationSweeper(time.Hour)
+	go jobs.RunTrashPurge(time.Hour)
+	go jobs.RunDueReminders(5 * time.Minute)
+
 	// --- STRUKTUR RUTE YANG DIPERBAIKI ---
 
 	// 1. Grup untuk rute publik (tanpa otentikasi)
@@ -34,7 +39,11 @@ func main() {
 	{
 		public.POST("/register", controllers.Register)
 		public.POST("/login", controllers.Login)
+		public.POST("/login/mfa", controllers.LoginMFA)
+		public.POST("/refresh", controllers.RefreshAccessToken)
+		public.POST("/logout", controllers.Logout)
 		public.GET("/verify", controllers.VerifyEmail)
+		public.GET("/invite", controllers.ShowInvitationPage)
 		public.POST("/forgot-password", controllers.ForgotPassword)
 		public.GET("/reset-password-page", controllers.ShowResetPasswordPage)
 		public.POST("/reset-password", controllers.ResetPassword)
@@ -44,31 +53,107 @@ func main() {
 	api := r.Group("/api")
 	api.Use(middlewares.AuthMiddleware())
 	{
+		api.GET("/me/sessions", controllers.GetMySessions)
+		api.DELETE("/me/sessions/:sessionId", controllers.RevokeMySession)
+		api.POST("/me/2fa/setup", controllers.SetupMFA)
+		api.POST("/me/2fa/confirm", controllers.ConfirmMFA)
+		api.POST("/me/2fa/disable", controllers.DisableMFA)
+		api.POST("/me/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+
 		api.POST("/teams", controllers.CreateTeam)
 		api.GET("/teams", controllers.GetMyTeams)
+		api.GET("/search", controllers.Search)
 
-		teamRoutes := api.Group("/teams/:teamId")
+		// Rute undangan milik user yang sedang login
+		api.GET("/invitations", controllers.GetMyInvitations)
+		api.POST("/invitations/:invitationId/respond", controllers.RespondToInvitation)
+		api.POST("/join/:code", controllers.JoinTeamWithLink)
+
+		// Kotak masuk notifikasi milik user yang sedang login
+		api.GET("/notifications", controllers.GetMyNotifications)
+		api.POST("/notifications/read-all", controllers.MarkAllNotificationsRead)
+		api.POST("/notifications/:notificationId/read", controllers.MarkNotificationRead)
 
-		teamRoutes.Use(middlewares.TeamMemberMiddleware())
+		// Tempat sampah tim milik user yang sedang login
+		api.GET("/trash/teams", controllers.GetMyDeletedTeams)
+		api.POST("/trash/teams/:teamId/restore", controllers.RestoreTeam)
+		api.DELETE("/trash/teams/:teamId", controllers.PurgeDeletedTeam)
+
+		// Semua rute tim minimal membutuhkan keanggotaan (izin view_team).
+		// Rute yang lebih sensitif menambahkan pengecekan izin sesuai matriks role.
+		teamRoutes := api.Group("/teams/:teamId")
+		teamRoutes.Use(middlewares.RequireTeamPermission(models.PermViewTeam))
 		{
-			// Rute yang hanya butuh keanggotaan
-			memberRoutes := teamRoutes.Group("")
-			memberRoutes.Use(middlewares.TeamMemberMiddleware())
-			memberRoutes.GET("", controllers.GetTeamDetails)
-			memberRoutes.POST("/invite", controllers.InviteUserToTeam)
-			memberRoutes.POST("/todos", controllers.CreateTodo)
+			teamRoutes.GET("", controllers.GetTeamDetails)
+			teamRoutes.GET("/members", controllers.GetTeamMembers)
 			teamRoutes.GET("/todos", controllers.GetTeamTodos)
-			teamRoutes.PUT("/todos/:todoId", controllers.UpdateTodo)
-			teamRoutes.DELETE("/todos/:todoId", controllers.DeleteTodo)
-			ownerRoutes := teamRoutes.Group("")
-			ownerRoutes.Use(middlewares.TeamOwnerMiddleware()) // Gunakan middleware baru
-			ownerRoutes.PUT("", controllers.UpdateTeam)        // PUT ke /api/teams/:teamId
-			ownerRoutes.DELETE("", controllers.DeleteTeam)     // DELETE ke /api/teams/:teamId
-			// Rute baru untuk manajemen undangan
-			api.GET("/invitations", controllers.GetMyInvitations)
-			api.POST("/invitations/:invitationId/respond", controllers.RespondToInvitation)
+			teamRoutes.POST("/leave", controllers.LeaveTeam)
+			teamRoutes.GET("/todos/:todoId/comments", controllers.GetTodoComments)
+			teamRoutes.GET("/todos/:todoId/checklist", controllers.GetChecklist)
+			teamRoutes.GET("/labels", controllers.GetTeamLabels)
+			teamRoutes.GET("/todos/:todoId/history", controllers.GetTodoHistory)
+			teamRoutes.GET("/activity", controllers.GetTeamActivity)
+			teamRoutes.GET("/statuses", controllers.GetTeamStatuses)
+			teamRoutes.GET("/statuses/transitions", controllers.GetStatusTransitions)
+			teamRoutes.GET("/trash", controllers.GetTeamTrash)
+			teamRoutes.GET("/presence", controllers.GetTeamPresence)
+
+			// Editor ke atas: berkomentar. Edit/hapus komentar dibatasi untuk penulisnya.
+			commentRoutes := teamRoutes.Group("/todos/:todoId/comments")
+			commentRoutes.Use(middlewares.RequireTeamPermission(models.PermComment))
+			commentRoutes.POST("", controllers.CreateComment)
+			commentRoutes.PUT("/:commentId", controllers.UpdateComment)
+			commentRoutes.DELETE("/:commentId", controllers.DeleteComment)
+
+			// Editor ke atas: kelola todo
+			todoRoutes := teamRoutes.Group("")
+			todoRoutes.Use(middlewares.RequireTeamPermission(models.PermManageTodos))
+			todoRoutes.POST("/todos", controllers.CreateTodo)
+			todoRoutes.PUT("/todos/:todoId", controllers.UpdateTodo)
+			todoRoutes.DELETE("/todos/:todoId", controllers.DeleteTodo)
+			todoRoutes.POST("/todos/:todoId/move", controllers.MoveTodo)
+			todoRoutes.POST("/todos/:todoId/assignees", controllers.AssignTodo)
+			todoRoutes.DELETE("/todos/:todoId/assignees/:userId", controllers.UnassignTodo)
+			todoRoutes.POST("/todos/:todoId/checklist", controllers.CreateChecklistItem)
+			todoRoutes.PUT("/todos/:todoId/checklist/order", controllers.ReorderChecklist)
+			todoRoutes.PATCH("/todos/:todoId/checklist/:itemId", controllers.UpdateChecklistItem)
+			todoRoutes.DELETE("/todos/:todoId/checklist/:itemId", controllers.DeleteChecklistItem)
+			todoRoutes.POST("/todos/:todoId/labels", controllers.AddTodoLabel)
+			todoRoutes.DELETE("/todos/:todoId/labels/:labelId", controllers.RemoveTodoLabel)
+			todoRoutes.POST("/labels", controllers.CreateLabel)
+			todoRoutes.PUT("/labels/:labelId", controllers.UpdateLabel)
+			todoRoutes.DELETE("/labels/:labelId", controllers.DeleteLabel)
+			todoRoutes.POST("/trash/:todoId/restore", controllers.RestoreTodo)
+			todoRoutes.DELETE("/trash/:todoId", controllers.PurgeTrashedTodo)
+
+			// Admin ke atas: kelola anggota
+			inviteRoutes := teamRoutes.Group("")
+			inviteRoutes.Use(middlewares.RequireTeamPermission(models.PermInviteMembers))
+			inviteRoutes.POST("/invite", controllers.InviteUserToTeam)
+			inviteRoutes.GET("/invitations", controllers.GetTeamInvitations)
+			inviteRoutes.DELETE("/invitations/:invitationId", controllers.RevokeInvitation)
+			inviteRoutes.POST("/invitations/:invitationId/resend", controllers.ResendInvitation)
+			inviteRoutes.POST("/join-links", controllers.CreateJoinLink)
+			inviteRoutes.GET("/join-links", controllers.GetJoinLinks)
+			inviteRoutes.DELETE("/join-links/:linkId", controllers.RevokeJoinLink)
+
+			// Pengaturan alur kerja (owner & admin)
+			flowRoutes := teamRoutes.Group("/statuses")
+			flowRoutes.Use(middlewares.RequireTeamPermission(models.PermManageFlow))
+			flowRoutes.POST("", controllers.CreateTeamStatus)
+			flowRoutes.PUT("/order", controllers.ReorderTeamStatuses)
+			flowRoutes.PUT("/transitions", controllers.SetStatusTransitions)
+			flowRoutes.PUT("/:statusId", controllers.UpdateTeamStatus)
+			flowRoutes.DELETE("/:statusId", controllers.DeleteTeamStatus)
+
+			teamRoutes.PUT("/members/:userId/role", middlewares.RequireTeamPermission(models.PermManageRoles), controllers.UpdateMemberRole)
+			teamRoutes.DELETE("/members/:userId", middlewares.RequireTeamPermission(models.PermRemoveMembers), controllers.RemoveTeamMember)
+
+			// Owner: kelola tim
+			teamRoutes.PUT("", middlewares.RequireTeamPermission(models.PermManageTeam), controllers.UpdateTeam)    // PUT ke /api/teams/:teamId
+			teamRoutes.DELETE("", middlewares.RequireTeamPermission(models.PermDeleteTeam), controllers.DeleteTeam) // DELETE ke /api/teams/:teamId
+			teamRoutes.POST("/transfer-ownership", middlewares.RequireTeamPermission(models.PermTransferTeam), controllers.TransferOwnership)
 		}
-
 	}
 
 	// 3. Grup TERPISAH khusus untuk WebSocket dengan middleware-nya sendiri
@@ -77,6 +162,7 @@ func main() {
 	wsApi.Use(middlewares.WsAuthMiddleware())
 	{
 		wsApi.GET("/teams/:teamId", controllers.ServeWs)
+		wsApi.GET("/me", controllers.ServeUserWs)
 	}
 
 	// Jalankan server
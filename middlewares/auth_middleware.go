@@ -3,14 +3,9 @@ package middlewares
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
-	"notedteam.backend/config"
-	"notedteam.backend/models" // Pastikan models diimpor
-
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -27,37 +22,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, http.ErrAbortHandler
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		// Validasi token sekaligus cek apakah sesinya masih aktif
+		if err := authenticateToken(c, parts[1]); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if ok && token.Valid {
-			userID := uint(claims["user_id"].(float64))
-
-			// --- PERBAIKAN PENTING DI SINI ---
-			// Selalu ambil data user lengkap dan simpan di context
-			var user models.User
-			if err := config.DB.First(&user, userID).Error; err != nil {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User associated with token not found"})
-				return
-			}
-
-			c.Set("user_id", user.ID) // Tetap set user_id untuk kemudahan
-			c.Set("user", user)       // Set objek user lengkap
-			c.Next()
-			// ---------------------------------
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		}
+		c.Next()
 	}
 }
@@ -0,0 +1,50 @@
+// middlewares/session.go
+package middlewares
+
+import (
+	"errors"
+	"time"
+
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+	"notedteam.backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authenticateToken dipakai bersama oleh AuthMiddleware dan WsAuthMiddleware.
+// Ia memvalidasi access token, memastikan sesi di baliknya belum dicabut,
+// lalu menyimpan user_id, user, dan session_id ke context.
+func authenticateToken(c *gin.Context, tokenString string) error {
+	claims, err := utils.ParseToken(tokenString, utils.TokenTypeAccess)
+	if err != nil {
+		return errors.New("Invalid or expired token")
+	}
+
+	userID, okUser := utils.ClaimUint(claims, "user_id")
+	sessionID, okSession := utils.ClaimUint(claims, "session_id")
+	if !okUser || !okSession {
+		return errors.New("Invalid token claims")
+	}
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil || !session.IsActive() {
+		return errors.New("Session has been revoked or expired")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return errors.New("User associated with token not found")
+	}
+
+	// Perbarui "terakhir aktif" paling sering sekali per menit agar tidak membebani database
+	now := time.Now()
+	if session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) > time.Minute {
+		config.DB.Model(&session).UpdateColumn("last_seen_at", now)
+	}
+
+	c.Set("user_id", user.ID) // Tetap set user_id untuk kemudahan
+	c.Set("user", user)       // Set objek user lengkap
+	c.Set("session_id", session.ID)
+	return nil
+}
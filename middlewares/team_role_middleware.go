@@ -0,0 +1,45 @@
+// middlewares/team_role_middleware.go
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"notedteam.backend/config"
+	"notedteam.backend/models"
+)
+
+// RequireTeamPermission memastikan user adalah anggota tim pada parameter :teamId
+// dan role-nya memiliki izin yang diminta. Role disimpan ke context sebagai "team_role"
+// sehingga middleware berikutnya pada rute yang sama tidak perlu query ulang.
+func RequireTeamPermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var role models.TeamRole
+
+		if cached, exists := c.Get("team_role"); exists {
+			role = cached.(models.TeamRole)
+		} else {
+			userID, _ := c.Get("user_id")
+			teamID := c.Param("teamId")
+
+			// Tim yang ada di tempat sampah tidak bisa diakses sampai dipulihkan
+			var member models.TeamMember
+			if err := config.DB.
+				Joins("JOIN teams ON teams.id = team_members.team_id AND teams.deleted_at IS NULL").
+				Where("team_members.user_id = ? AND team_members.team_id = ?", userID, teamID).
+				First(&member).Error; err != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this team"})
+				return
+			}
+			role = member.Role
+			c.Set("team_role", role)
+		}
+
+		if !role.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Your role in this team does not allow this action"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,14 +3,9 @@ package middlewares
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
-	"notedteam.backend/config"
-	"notedteam.backend/models"
-
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // WsAuthMiddleware adalah middleware otentikasi yang fleksibel untuk WebSocket.
@@ -39,34 +34,12 @@ func WsAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Proses validasi token (sama seperti AuthMiddleware)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, http.ErrAbortHandler
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		// Proses validasi token (sama seperti AuthMiddleware), termasuk status sesi
+		if err := authenticateToken(c, tokenString); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if ok && token.Valid {
-			userID := uint(claims["user_id"].(float64))
-			var user models.User
-			if err := config.DB.First(&user, userID).Error; err != nil {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User associated with token not found"})
-				return
-			}
-
-			// Set user_id dan objek user ke context
-			c.Set("user_id", user.ID)
-			c.Set("user", user)
-			c.Next()
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		}
+		c.Next()
 	}
 }
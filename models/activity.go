@@ -0,0 +1,30 @@
+// models/activity.go
+package models
+
+import "time"
+
+// Aksi yang dicatat pada riwayat todo.
+const (
+	ActivityCreated    = "created"
+	ActivityUpdated    = "updated"
+	ActivityDeleted    = "deleted"
+	ActivityRestored   = "restored"
+	ActivityAssigned   = "assigned"
+	ActivityUnassigned = "unassigned"
+)
+
+// TodoActivity adalah satu baris riwayat perubahan todo. Perubahan beberapa field
+// sekaligus dicatat sebagai beberapa baris dengan waktu yang sama.
+// Tidak ada foreign key ke todos agar riwayat tetap ada setelah todo dihapus.
+type TodoActivity struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	TeamID    uint      `json:"team_id" gorm:"index"`
+	TodoID    uint      `json:"todo_id" gorm:"index"`
+	ActorID   uint      `json:"actor_id"`
+	Actor     User      `json:"actor" gorm:"foreignKey:ActorID"`
+	Action    string    `json:"action" gorm:"size:20;not null"`
+	Field     string    `json:"field,omitempty" gorm:"size:50"` // Kosong untuk created/deleted
+	OldValue  *string   `json:"old_value" gorm:"type:text"`
+	NewValue  *string   `json:"new_value" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+// models/checklist.go
+package models
+
+import "time"
+
+// ChecklistItem adalah subtugas di dalam sebuah todo.
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	TodoID    uint      `json:"todo_id" gorm:"index"`
+	Title     string    `json:"title" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"default:false"`
+	Position  int       `json:"position"` // Urutan tampil, dimulai dari 0
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistProgress meringkas jumlah item checklist yang sudah selesai pada sebuah todo.
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
@@ -0,0 +1,19 @@
+// models/comment.go
+package models
+
+import "time"
+
+// Comment adalah diskusi pada sebuah todo. Body berformat markdown dan
+// komentar bisa membalas komentar lain (ParentID) untuk membentuk thread.
+type Comment struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	TodoID    uint       `json:"todo_id" gorm:"index"`
+	TeamID    uint       `json:"team_id" gorm:"index"`
+	AuthorID  uint       `json:"author_id"`
+	Author    User       `json:"author" gorm:"foreignKey:AuthorID"`
+	ParentID  *uint      `json:"parent_id" gorm:"index"` // null = komentar tingkat atas
+	Body      string     `json:"body" gorm:"type:text;not null;index:idx_comments_fulltext,class:FULLTEXT"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
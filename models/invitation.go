@@ -9,15 +9,26 @@ const (
 	InvitationPending  InvitationStatus = "pending"
 	InvitationAccepted InvitationStatus = "accepted"
 	InvitationDeclined InvitationStatus = "declined"
+	InvitationRevoked  InvitationStatus = "revoked" // Dibatalkan oleh pengundang
+	InvitationExpired  InvitationStatus = "expired" // Melewati ExpiresAt sebelum direspons
 )
 
 type Invitation struct {
-	ID        uint             `json:"id" gorm:"primary_key"`
-	UserID    uint             `json:"user_id"` // Siapa yang diundang
-	TeamID    uint             `json:"team_id"` // Ke tim mana
-	Status    InvitationStatus `json:"status" gorm:"type:enum('pending','accepted','declined');default:'pending'"`
-	User      User             `json:"-" gorm:"foreignKey:UserID"`
-	Team      Team             `json:"team" gorm:"foreignKey:TeamID"` // Sertakan data tim
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
+	ID          uint             `json:"id" gorm:"primary_key"`
+	UserID      *uint            `json:"user_id"`                     // Siapa yang diundang (null jika belum punya akun)
+	Email       string           `json:"email" gorm:"size:255;index"` // Email yang diundang
+	TeamID      uint             `json:"team_id"`                     // Ke tim mana
+	InvitedByID *uint            `json:"invited_by_id"`               // Siapa yang mengundang
+	Status      InvitationStatus `json:"status" gorm:"type:enum('pending','accepted','declined','revoked','expired');default:'pending'"`
+	ExpiresAt   *time.Time       `json:"expires_at"`
+	User        User             `json:"-" gorm:"foreignKey:UserID"`
+	InvitedBy   *User            `json:"invited_by,omitempty" gorm:"foreignKey:InvitedByID"`
+	Team        Team             `json:"team" gorm:"foreignKey:TeamID"` // Sertakan data tim
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// IsExpired mengembalikan true jika masa berlaku undangan sudah lewat.
+func (i Invitation) IsExpired() bool {
+	return i.ExpiresAt != nil && i.ExpiresAt.Before(time.Now())
 }
@@ -0,0 +1,30 @@
+// models/join_link.go
+package models
+
+import "time"
+
+// TeamJoinLink adalah link bergabung yang bisa dibagikan ke banyak orang sekaligus.
+type TeamJoinLink struct {
+	ID          uint       `json:"id" gorm:"primary_key"`
+	TeamID      uint       `json:"team_id" gorm:"index"`
+	Code        string     `json:"code" gorm:"size:64;uniqueIndex;not null"`
+	CreatedByID uint       `json:"created_by_id"`
+	DefaultRole TeamRole   `json:"default_role" gorm:"type:enum('owner','admin','editor','viewer');default:'editor'"` // Role untuk anggota yang bergabung
+	MaxUses     *int       `json:"max_uses"`                                                                          // null = tanpa batas
+	UseCount    int        `json:"use_count" gorm:"default:0"`
+	ExpiresAt   *time.Time `json:"expires_at"` // null = tidak kedaluwarsa
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsUsable mengembalikan true jika link belum dicabut, belum kedaluwarsa, dan kuotanya belum habis.
+func (l TeamJoinLink) IsUsable() bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && l.ExpiresAt.Before(time.Now()) {
+		return false
+	}
+	return l.MaxUses == nil || l.UseCount < *l.MaxUses
+}
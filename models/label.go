@@ -0,0 +1,14 @@
+// models/label.go
+package models
+
+import "time"
+
+// Label adalah kategori milik tim yang bisa ditempelkan ke banyak todo.
+type Label struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	TeamID    uint      `json:"team_id" gorm:"uniqueIndex:idx_team_label_name"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_team_label_name"` // Unik di dalam satu tim
+	Color     string    `json:"color" gorm:"size:7;not null"`                                 // Format hex, mis. #ff5722
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,67 @@
+// models/notification.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Jenis notifikasi di kotak masuk user.
+const (
+	NotificationInvitation = "invitation"
+	NotificationAssigned   = "assigned"
+	NotificationComment    = "comment"
+	NotificationMention    = "mention"
+	NotificationDueSoon    = "due_soon"
+	NotificationOverdue    = "overdue"
+)
+
+// Notification adalah satu item di kotak masuk user. Notifikasi ikut terhapus saat
+// tim atau todo-nya dihapus permanen.
+type Notification struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	UserID       uint      `json:"user_id" gorm:"not null;index:idx_notifications_user_read"` // Penerima
+	Type         string    `json:"type" gorm:"size:30;not null"`
+	ActorID      *uint     `json:"actor_id"` // Null untuk notifikasi dari sistem (pengingat)
+	Actor        *User     `json:"actor,omitempty" gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL"`
+	TeamID       *uint     `json:"team_id"`
+	Team         *Team     `json:"team,omitempty" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+	TodoID       *uint     `json:"todo_id"`
+	Todo         *Todo     `json:"todo,omitempty" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	CommentID    *uint     `json:"comment_id,omitempty"`
+	InvitationID *uint     `json:"invitation_id,omitempty"`
+	Read         bool      `json:"read" gorm:"column:is_read;not null;default:false;index:idx_notifications_user_read"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PreloadNotification memuat relasi yang disertakan saat mengirim notifikasi ke klien.
+func PreloadNotification(db *gorm.DB) *gorm.DB {
+	return db.Preload("Actor").Preload("Team").Preload("Todo")
+}
+
+// CreateNotifications menyimpan notifikasi lalu mengembalikannya beserta relasinya.
+// Notifikasi untuk aksi user sendiri (ActorID == UserID) dilewati.
+func CreateNotifications(db *gorm.DB, notifications []Notification) ([]Notification, error) {
+	var pending []Notification
+	for _, n := range notifications {
+		if n.ActorID != nil && *n.ActorID == n.UserID {
+			continue
+		}
+		pending = append(pending, n)
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	if err := db.Create(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(pending))
+	for i, n := range pending {
+		ids[i] = n.ID
+	}
+	var created []Notification
+	err := PreloadNotification(db).Where("id IN ?", ids).Order("id asc").Find(&created).Error
+	return created, err
+}
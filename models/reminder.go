@@ -0,0 +1,21 @@
+// models/reminder.go
+package models
+
+import "time"
+
+// Jenis pengingat tenggat todo.
+const (
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+)
+
+// TodoReminder mencatat pengingat yang sudah dikirim untuk sebuah todo, agar tidak
+// terkirim dua kali setelah server restart. DueDate ikut menjadi kunci sehingga
+// pengingat dikirim lagi jika tenggat todo diubah.
+type TodoReminder struct {
+	ID      uint      `json:"id" gorm:"primary_key"`
+	TodoID  uint      `json:"todo_id" gorm:"not null;uniqueIndex:idx_todo_reminder"`
+	Kind    string    `json:"kind" gorm:"size:20;not null;uniqueIndex:idx_todo_reminder"`
+	DueDate time.Time `json:"due_date" gorm:"not null;uniqueIndex:idx_todo_reminder"`
+	SentAt  time.Time `json:"sent_at"`
+}
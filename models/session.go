@@ -0,0 +1,53 @@
+// models/session.go
+package models
+
+import "time"
+
+// Session mewakili satu kali login. Semua refresh token hasil rotasi dari login
+// yang sama berada dalam satu "keluarga" sesi ini.
+type Session struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Terisi jika sesi dicabut (logout / reuse terdeteksi)
+
+	// Informasi perangkat untuk ditampilkan di daftar sesi aktif
+	DeviceName string     `json:"device_name" gorm:"size:100"`
+	UserAgent  string     `json:"user_agent" gorm:"size:255"`
+	IPAddress  string     `json:"ip_address" gorm:"size:45"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Current    bool       `json:"current" gorm:"-"` // Diisi saat listing: apakah ini sesi yang sedang dipakai
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsActive mengembalikan true jika sesi belum dicabut dan belum kedaluwarsa.
+func (s Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
+
+// MFAChallenge mewakili satu token tantangan 2FA yang diterbitkan saat login. Token
+// hanya bisa ditukar sekali, dan kode yang salah dihitung untuk membatasi tebakan.
+type MFAChallenge struct {
+	ID             uint       `json:"id" gorm:"primary_key"`
+	UserID         uint       `json:"user_id" gorm:"index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	TokenID        string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // Claim jti pada token tantangan
+	FailedAttempts int        `json:"failed_attempts" gorm:"not null;default:0"`
+	UsedAt         *time.Time `json:"used_at,omitempty"` // Terisi setelah ditukar dengan sesi
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RefreshToken menyimpan hash dari setiap refresh token yang pernah diterbitkan.
+// Token yang sudah dipakai (UsedAt terisi) tidak boleh dipakai lagi.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	SessionID uint       `json:"session_id" gorm:"index"`
+	Session   Session    `json:"-" gorm:"foreignKey:SessionID"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
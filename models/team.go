@@ -1,7 +1,11 @@
 // models/team.go
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Team struct {
 	ID        uint      `json:"id" gorm:"primary_key"`
@@ -12,4 +16,35 @@ type Team struct {
 	Todos     []Todo    `json:"todos,omitempty"` // Sebuah tim memiliki banyak todo
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // Terisi jika tim ada di tempat sampah
+}
+
+// PurgeTeam menghapus permanen tim beserta semua datanya, termasuk todo yang
+// sudah ada di tempat sampah.
+func PurgeTeam(tx *gorm.DB, teamID uint) error {
+	var todoIDs []uint
+	if err := tx.Unscoped().Model(&Todo{}).Where("team_id = ?", teamID).Pluck("id", &todoIDs).Error; err != nil {
+		return err
+	}
+	if err := PurgeTodos(tx, todoIDs); err != nil {
+		return err
+	}
+
+	// Data milik tim yang tidak bergantung pada todo
+	for _, model := range []interface{}{
+		&TeamMember{},
+		&TeamJoinLink{},
+		&Invitation{},
+		&Label{},
+		&StatusTransition{},
+		&TeamStatus{},
+		&TodoActivity{},
+	} {
+		if err := tx.Where("team_id = ?", teamID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Delete(&Team{}, teamID).Error
 }
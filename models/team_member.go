@@ -0,0 +1,73 @@
+// models/team_member.go
+package models
+
+// TeamRole menentukan apa saja yang boleh dilakukan seorang anggota di dalam tim.
+type TeamRole string
+
+const (
+	RoleOwner  TeamRole = "owner"
+	RoleAdmin  TeamRole = "admin"
+	RoleEditor TeamRole = "editor"
+	RoleViewer TeamRole = "viewer"
+)
+
+// Permission adalah aksi yang dijaga oleh middleware RequireTeamPermission.
+type Permission string
+
+const (
+	PermViewTeam      Permission = "view_team"      // Melihat tim, anggota, dan todo
+	PermManageTodos   Permission = "manage_todos"   // Membuat, mengubah, menghapus todo
+	PermComment       Permission = "comment"        // Menulis komentar pada todo
+	PermInviteMembers Permission = "invite_members" // Mengundang anggota baru
+	PermRemoveMembers Permission = "remove_members" // Mengeluarkan anggota
+	PermManageRoles   Permission = "manage_roles"   // Mengubah role anggota lain
+	PermManageFlow    Permission = "manage_flow"    // Mengatur status alur kerja dan transisinya
+	PermManageTeam    Permission = "manage_team"    // Mengubah pengaturan tim
+	PermDeleteTeam    Permission = "delete_team"    // Menghapus tim
+	PermTransferTeam  Permission = "transfer_team"  // Menyerahkan kepemilikan tim
+)
+
+// rolePermissions adalah matriks izin untuk setiap role.
+var rolePermissions = map[TeamRole][]Permission{
+	RoleOwner:  {PermViewTeam, PermManageTodos, PermComment, PermInviteMembers, PermRemoveMembers, PermManageRoles, PermManageFlow, PermManageTeam, PermDeleteTeam, PermTransferTeam},
+	RoleAdmin:  {PermViewTeam, PermManageTodos, PermComment, PermInviteMembers, PermRemoveMembers, PermManageRoles, PermManageFlow},
+	RoleEditor: {PermViewTeam, PermManageTodos, PermComment},
+	RoleViewer: {PermViewTeam},
+}
+
+// roleRanks dipakai untuk membandingkan tingkat role (semakin besar semakin tinggi).
+var roleRanks = map[TeamRole]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// IsValid mengembalikan true jika role dikenal.
+func (r TeamRole) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Can mengembalikan true jika role memiliki izin tertentu.
+func (r TeamRole) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Outranks mengembalikan true jika role ini lebih tinggi dari role lain.
+func (r TeamRole) Outranks(other TeamRole) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// TeamMember adalah model untuk join table team_members (relasi many2many User <-> Team)
+// yang sekarang juga menyimpan role anggota.
+type TeamMember struct {
+	TeamID uint     `json:"team_id" gorm:"primaryKey"`
+	UserID uint     `json:"user_id" gorm:"primaryKey"`
+	Role   TeamRole `json:"role" gorm:"type:enum('owner','admin','editor','viewer');default:'editor'"`
+}
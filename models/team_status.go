@@ -0,0 +1,81 @@
+// models/team_status.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TeamStatus adalah kolom alur kerja milik tim (mis. Backlog, In Review, Done).
+// Todo.Status yang lama tetap diisi dari status ini agar klien lama tetap berjalan.
+type TeamStatus struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	TeamID    uint      `json:"team_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	IsDone    bool      `json:"is_done" gorm:"default:false"` // Todo di status ini dianggap selesai
+	Position  int       `json:"position"`                     // Urutan kolom, dimulai dari 0
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StatusTransition adalah perpindahan status yang diizinkan di dalam tim.
+// Jika tim tidak memiliki transisi sama sekali, semua perpindahan diizinkan.
+type StatusTransition struct {
+	ID           uint `json:"id" gorm:"primary_key"`
+	TeamID       uint `json:"team_id" gorm:"index"`
+	FromStatusID uint `json:"from_status_id" gorm:"uniqueIndex:idx_status_transition"`
+	ToStatusID   uint `json:"to_status_id" gorm:"uniqueIndex:idx_status_transition"`
+}
+
+// defaultTeamStatuses meniru enum lama pending/working/completed.
+var defaultTeamStatuses = []TeamStatus{
+	{Name: "Pending", Position: 0},
+	{Name: "Working", Position: 1},
+	{Name: "Completed", Position: 2, IsDone: true},
+}
+
+// CreateDefaultStatuses membuat status bawaan untuk tim baru.
+func CreateDefaultStatuses(tx *gorm.DB, teamID uint) error {
+	statuses := make([]TeamStatus, len(defaultTeamStatuses))
+	for i, status := range defaultTeamStatuses {
+		status.TeamID = teamID
+		statuses[i] = status
+	}
+	return tx.Create(&statuses).Error
+}
+
+// LegacyStatus menerjemahkan status tim ke enum lama: status selesai menjadi completed,
+// status pertama tim menjadi pending, sisanya working.
+func (s TeamStatus) LegacyStatus(first TeamStatus) StatusType {
+	switch {
+	case s.IsDone:
+		return StatusCompleted
+	case s.ID == first.ID:
+		return StatusPending
+	default:
+		return StatusWorking
+	}
+}
+
+// SyncLegacyStatuses menulis ulang kolom todos.status untuk semua todo di tim
+// setelah status tim diubah, diurutkan ulang, atau dihapus. Todo yang berubah
+// mendapat versi baru agar klien dengan salinan lama menerima 409.
+func SyncLegacyStatuses(tx *gorm.DB, teamID uint) error {
+	var statuses []TeamStatus
+	if err := tx.Where("team_id = ?", teamID).Order("position asc, id asc").Find(&statuses).Error; err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	for _, status := range statuses {
+		legacy := status.LegacyStatus(statuses[0])
+		if err := tx.Unscoped().Model(&Todo{}).
+			Where("status_id = ? AND status <> ?", status.ID, legacy).
+			UpdateColumns(map[string]interface{}{"status": legacy, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
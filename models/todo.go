@@ -1,7 +1,11 @@
 // models/todo.go
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Definisikan tipe kustom untuk status dan urgensi agar lebih terstruktur
 type StatusType string
@@ -21,9 +25,11 @@ const (
 
 type Todo struct {
 	ID          uint        `json:"id" gorm:"primary_key"`
-	Title       string      `json:"title" gorm:"not null"`
-	Description string      `json:"description"`
-	Status      StatusType  `json:"status" gorm:"type:enum('pending','working','completed');default:'pending'"`
+	Title       string      `json:"title" gorm:"not null;index:idx_todos_fulltext,class:FULLTEXT"`
+	Description string      `json:"description" gorm:"index:idx_todos_fulltext,class:FULLTEXT"`
+	Status      StatusType  `json:"status" gorm:"type:enum('pending','working','completed');default:'pending'"` // Diturunkan dari TeamStatus
+	StatusID    *uint       `json:"status_id" gorm:"index"`                                                     // Status alur kerja tim
+	TeamStatus  *TeamStatus `json:"team_status,omitempty" gorm:"foreignKey:StatusID"`
 	Urgency     UrgencyType `json:"urgency" gorm:"type:enum('low','medium','high');default:'low'"`
 
 	// --- PERUBAHAN ---
@@ -33,7 +39,59 @@ type Todo struct {
 	EditorID  uint       `json:"editor_id"`
 	Creator   User       `json:"creator,omitempty" gorm:"foreignKey:CreatorID"`
 	Editor    User       `json:"editor,omitempty" gorm:"foreignKey:EditorID"`
+	Assignees []User     `json:"assignees" gorm:"many2many:todo_assignees;"` // Anggota tim yang mengerjakan todo ini
+	Labels    []Label    `json:"labels" gorm:"many2many:todo_labels;"`
 	DueDate   *time.Time `json:"due_date,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Jika true, todo otomatis berstatus completed saat semua item checklist selesai
+	AutoCompleteChecklist bool              `json:"auto_complete_checklist" gorm:"default:false"`
+	ChecklistProgress     ChecklistProgress `json:"checklist_progress" gorm:"-"` // Diisi saat listing
+
+	// Urutan kartu di dalam kolom status (papan kanban), dibandingkan sebagai string
+	Rank string `json:"rank" gorm:"size:191;index"`
+
+	// Naik setiap kali todo diubah; dipakai sebagai ETag untuk mencegah perubahan saling menimpa
+	Version uint `json:"version" gorm:"not null;default:1"`
+
+	// Aturan pengulangan (subset RRULE, lihat utils.ParseRecurrence). Kosong jika tidak berulang.
+	// Saat todo diselesaikan, kejadian berikutnya dibuat dan ID-nya disimpan di NextOccurrenceID.
+	Recurrence       string `json:"recurrence" gorm:"size:255"`
+	NextOccurrenceID *uint  `json:"next_occurrence_id"`
+
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // Terisi jika todo ada di tempat sampah
+}
+
+// DeleteTodoDependents menghapus semua data yang bergantung pada todo (relasi, dll.)
+// agar todo-todo tersebut bisa dihapus permanen tanpa melanggar foreign key.
+func DeleteTodoDependents(tx *gorm.DB, todoIDs []uint) error {
+	if len(todoIDs) == 0 {
+		return nil
+	}
+	if err := tx.Exec("DELETE FROM todo_assignees WHERE todo_id IN ?", todoIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM todo_labels WHERE todo_id IN ?", todoIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("todo_id IN ?", todoIDs).Delete(&ChecklistItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("todo_id IN ?", todoIDs).Delete(&TodoReminder{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("todo_id IN ?", todoIDs).Delete(&Comment{}).Error
+}
+
+// PurgeTodos menghapus permanen todo (termasuk yang ada di tempat sampah) beserta
+// data turunannya. Riwayat aktivitas tetap disimpan.
+func PurgeTodos(tx *gorm.DB, todoIDs []uint) error {
+	if len(todoIDs) == 0 {
+		return nil
+	}
+	if err := DeleteTodoDependents(tx, todoIDs); err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", todoIDs).Delete(&Todo{}).Error
 }
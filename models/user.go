@@ -12,7 +12,20 @@ type User struct {
 	VerificationToken     string     `json:"-" gorm:"size:255"`
 	PasswordResetToken    string     `json:"-" gorm:"size:255"`
 	PasswordResetTokenExp *time.Time `json:"-"`
+	TOTPSecret            string     `json:"-" gorm:"size:64"` // Secret authenticator (base32)
+	TOTPEnabled           bool       `json:"totp_enabled" gorm:"default:false"`
+	TOTPLastStep          int64      `json:"-"` // Langkah waktu TOTP terakhir yang dipakai, untuk mencegah replay
 	CreatedAt             time.Time  `json:"created_at"`
 	UpdatedAt             time.Time  `json:"updated_at"`
 	Teams                 []Team     `json:"teams,omitempty" gorm:"many2many:team_members;"`
 }
+
+// RecoveryCode adalah kode pemulihan 2FA sekali pakai. Hanya hash-nya yang disimpan.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
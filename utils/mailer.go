@@ -2,9 +2,13 @@
 package utils
 
 import (
+	"html"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -69,3 +73,69 @@ func SendPasswordResetEmail(toEmail, token string) error {
 	}
 	return nil
 }
+
+// appBaseURL mengembalikan URL publik server untuk link di dalam email.
+func appBaseURL() string {
+	if baseURL := os.Getenv("APP_BASE_URL"); baseURL != "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	return "https://noble-energy-production-d0ae.up.railway.app"
+}
+
+// sendEmail mengirim email HTML memakai konfigurasi SMTP dari environment.
+func sendEmail(toEmail, subject, body string) error {
+	host := os.Getenv("SMTP_HOST")
+	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	sender := os.Getenv("SMTP_SENDER_EMAIL")
+	password := os.Getenv("SMTP_SENDER_PASSWORD")
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", sender)
+	mailer.SetHeader("To", toEmail)
+	mailer.SetHeader("Subject", subject)
+	mailer.SetBody("text/html", body)
+
+	dialer := gomail.NewDialer(host, port, sender, password)
+
+	log.Printf("Sending \"%s\" email to %s", subject, toEmail)
+	if err := dialer.DialAndSend(mailer); err != nil {
+		log.Printf("Failed to send email: %s", err)
+		return err
+	}
+	return nil
+}
+
+// SendTeamInvitationEmail mengundang orang yang belum punya akun untuk bergabung ke tim.
+func SendTeamInvitationEmail(toEmail, teamName, inviterName, token string) error {
+	inviteLink := appBaseURL() + "/auth/invite?token=" + url.QueryEscape(token)
+
+	body := "Hi there,<br><br>" + html.EscapeString(inviterName) + " has invited you to join the team <b>" + html.EscapeString(teamName) + "</b> on NotedTeam.<br>"
+	body += "<a href=\"" + inviteLink + "\">View Invitation</a><br><br>"
+	body += "Sign up in the NotedTeam app using this email address and the invitation will be waiting for you. "
+	body += "If you were not expecting this invitation, you can safely ignore this email."
+
+	return sendEmail(toEmail, "You're invited to join "+teamName+" on NotedTeam", body)
+}
+
+// SendInvitationReminderEmail mengingatkan user terdaftar bahwa ada undangan tim yang menunggu di aplikasi.
+func SendInvitationReminderEmail(toEmail, teamName, inviterName string) error {
+	body := "Hi there,<br><br>" + html.EscapeString(inviterName) + " has invited you to join the team <b>" + html.EscapeString(teamName) + "</b> on NotedTeam.<br><br>"
+	body += "Open the NotedTeam app to accept or decline the invitation."
+
+	return sendEmail(toEmail, "Reminder: you're invited to join "+teamName+" on NotedTeam", body)
+}
+
+// SendDueReminderEmail mengingatkan anggota bahwa sebuah todo akan jatuh tempo atau sudah lewat tenggat.
+func SendDueReminderEmail(toEmail, todoTitle, teamName string, dueDate time.Time, overdue bool) error {
+	due := dueDate.Format("Mon, 02 Jan 2006 15:04 MST")
+
+	subject := "Reminder: \"" + todoTitle + "\" is due soon"
+	body := "Hi there,<br><br>The to-do <b>" + html.EscapeString(todoTitle) + "</b> in the team <b>" + html.EscapeString(teamName) + "</b> is due on " + due + ".<br><br>"
+	if overdue {
+		subject = "Overdue: \"" + todoTitle + "\""
+		body = "Hi there,<br><br>The to-do <b>" + html.EscapeString(todoTitle) + "</b> in the team <b>" + html.EscapeString(teamName) + "</b> was due on " + due + " and is not completed yet.<br><br>"
+	}
+	body += "Open the NotedTeam app to update it."
+
+	return sendEmail(toEmail, subject, body)
+}
@@ -0,0 +1,24 @@
+// utils/mention.go
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern mencocokkan mention berbentuk @email, mis. "@budi@example.com".
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.%+\-]+@[\w\-]+(?:\.[\w\-]+)+)`)
+
+// ExtractMentions mengembalikan email unik (huruf kecil) yang di-mention di text.
+func ExtractMentions(text string) []string {
+	seen := map[string]bool{}
+	var emails []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
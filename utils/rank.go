@@ -0,0 +1,92 @@
+// utils/rank.go
+package utils
+
+import "strings"
+
+// rankDigits adalah alfabet rank. Urutannya sama dengan urutan string biasa
+// dan collation MySQL, jadi rank bisa diurutkan langsung dengan ORDER BY.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankDigits)
+
+func rankDigit(s string, i int) int {
+	return strings.IndexByte(rankDigits, s[i])
+}
+
+// RankBetween mengembalikan rank yang berada di antara prev dan next.
+// String kosong berarti tanpa batas (awal atau akhir kolom). prev harus lebih kecil
+// dari next, dan keduanya tidak boleh diakhiri '0' (semua rank dari package ini memenuhinya).
+func RankBetween(prev, next string) string {
+	var result []byte
+	bounded := next != ""
+
+	for i := 0; ; i++ {
+		prevDone := i >= len(prev)
+		p := 0
+		if !prevDone {
+			p = rankDigit(prev, i)
+		}
+		n := rankBase
+		if bounded && i < len(next) {
+			n = rankDigit(next, i)
+		}
+
+		if p == n {
+			result = append(result, rankDigits[p])
+			continue
+		}
+
+		if n-p > 1 {
+			var d int
+			switch {
+			case prevDone && bounded:
+				// Menyisipkan di awal: langkah kecil ke bawah agar rank tetap pendek
+				d = n - 1
+			case !prevDone && !bounded:
+				// Menyisipkan di akhir: langkah kecil ke atas
+				d = p + 1
+			default:
+				d = (p + n) / 2
+			}
+			return string(append(result, rankDigits[d]))
+		}
+
+		// Tidak ada ruang di posisi ini: pakai digit prev lalu lanjut tanpa batas atas
+		result = append(result, rankDigits[p])
+		bounded = false
+	}
+}
+
+// RankSequence membuat n rank berjarak rata dengan panjang yang sama,
+// dipakai untuk mengisi atau menyeimbangkan ulang urutan satu kolom.
+func RankSequence(n int) []string {
+	width := 1
+	for capacity := rankBase; capacity <= n; capacity *= rankBase {
+		width++
+	}
+	// Satu digit ekstra memberi ruang sisip di antara rank yang berdekatan
+	width++
+
+	space := 1
+	for i := 0; i < width; i++ {
+		space *= rankBase
+	}
+	step := space / (n + 1)
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := (i + 1) * step
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%rankBase]
+			value /= rankBase
+		}
+		rank := string(digits)
+		if strings.HasSuffix(rank, "0") {
+			// Rank tidak boleh diakhiri '0' agar selalu ada ruang sebelum rank tersebut
+			rank += "i"
+		}
+		ranks[i] = rank
+	}
+	return ranks
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkRankBetween memastikan rank berada di antara prev dan next (kosong berarti tanpa batas)
+// dan tidak diakhiri '0'.
+func checkRankBetween(t *testing.T, prev, rank, next string) {
+	t.Helper()
+	if rank == "" || strings.HasSuffix(rank, "0") {
+		t.Fatalf("RankBetween(%q, %q) = %q, want a non-empty rank not ending in '0'", prev, next, rank)
+	}
+	if prev != "" && rank <= prev {
+		t.Fatalf("RankBetween(%q, %q) = %q, want it after %q", prev, next, rank, prev)
+	}
+	if next != "" && rank >= next {
+		t.Fatalf("RankBetween(%q, %q) = %q, want it before %q", prev, next, rank, next)
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		prev, next string
+	}{
+		{"", ""},
+		{"", "i"},
+		{"", "1"},
+		{"", "01"},
+		{"", "0i"},
+		{"", "001"},
+		{"i", ""},
+		{"z", ""},
+		{"zz", ""},
+		{"a", "b"},
+		{"a", "c"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"0i", "1"},
+		{"01", "02"},
+		{"y", "z"},
+		{"5zzz", "6"},
+	}
+
+	for _, tt := range tests {
+		checkRankBetween(t, tt.prev, RankBetween(tt.prev, tt.next), tt.next)
+	}
+}
+
+func TestRankBetweenRepeatedInserts(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next string                                   // Batas sisip pertama
+		insert     func(prev, next string) (string, string) // Batas sisip berikutnya dari batas sebelumnya
+	}{
+		{
+			// Selalu di awal kolom: rank baru menjadi batas atas berikutnya
+			name: "top of column", prev: "", next: "i",
+			insert: func(prev, next string) (string, string) { return "", RankBetween("", next) },
+		},
+		{
+			// Selalu di akhir kolom: rank baru menjadi batas bawah berikutnya
+			name: "bottom of column", prev: "i", next: "",
+			insert: func(prev, next string) (string, string) { return RankBetween(prev, ""), "" },
+		},
+		{
+			// Selalu tepat setelah kartu yang sama
+			name: "after the same card", prev: "a", next: "b",
+			insert: func(prev, next string) (string, string) { return prev, RankBetween(prev, next) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, next := tt.prev, tt.next
+			for i := 0; i < 200; i++ {
+				rank := RankBetween(prev, next)
+				checkRankBetween(t, prev, rank, next)
+				if len(rank) > 60 {
+					t.Fatalf("insert %d: rank %q grew too long", i+1, rank)
+				}
+				prev, next = tt.insert(prev, next)
+			}
+		})
+	}
+}
+
+func TestRankSequence(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 35, 36, 37, 100, 1296, 1500} {
+		ranks := RankSequence(n)
+		if len(ranks) != n {
+			t.Fatalf("RankSequence(%d) returned %d ranks", n, len(ranks))
+		}
+		for i, rank := range ranks {
+			if strings.HasSuffix(rank, "0") {
+				t.Fatalf("RankSequence(%d)[%d] = %q ends in '0'", n, i, rank)
+			}
+			if i > 0 && ranks[i-1] >= rank {
+				t.Fatalf("RankSequence(%d) not increasing at %d: %q >= %q", n, i, ranks[i-1], rank)
+			}
+		}
+
+		// Harus selalu ada ruang untuk menyisip di awal, di antara, dan di akhir
+		if n > 0 {
+			checkRankBetween(t, "", RankBetween("", ranks[0]), ranks[0])
+			checkRankBetween(t, ranks[n-1], RankBetween(ranks[n-1], ""), "")
+		}
+		for i := 1; i < n; i++ {
+			checkRankBetween(t, ranks[i-1], RankBetween(ranks[i-1], ranks[i]), ranks[i])
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// utils/recurrence.go
+package utils
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Zona waktu TZID tetap bisa dimuat di server tanpa paket tzdata
+)
+
+// Frekuensi pengulangan yang didukung.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+const maxRecurrenceInterval = 365
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence adalah aturan pengulangan hasil ParseRecurrence.
+type Recurrence struct {
+	Freq       string
+	Interval   int            // Setiap N hari/minggu/bulan, minimal 1
+	ByDay      []time.Weekday // Hanya untuk WEEKLY; kosong berarti hari yang sama dengan tanggal awal
+	ByMonthDay int            // Hanya untuk MONTHLY; 1..31 atau -1 (hari terakhir), 0 berarti tanggal awal
+
+	// Zona waktu (TZID) tempat hari dan tanggal dihitung, mis. Asia/Jakarta.
+	// nil berarti zona waktu dari tanggal yang diberikan ke Anchor/Next.
+	Location *time.Location
+}
+
+// ParseRecurrence membaca subset RRULE (RFC 5545), mis. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"
+// atau "FREQ=MONTHLY;BYMONTHDAY=-1". Bagian yang didukung: FREQ (DAILY, WEEKLY, MONTHLY),
+// INTERVAL, BYDAY (WEEKLY), BYMONTHDAY (MONTHLY) dan TZID (nama zona waktu IANA, ekstensi
+// di luar RRULE baku). Awalan "RRULE:" boleh disertakan.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	rule = strings.TrimSpace(rule)
+	if len(rule) >= 6 && strings.EqualFold(rule[:6], "RRULE:") {
+		rule = rule[6:]
+	}
+	if rule == "" {
+		return r, errors.New("recurrence rule is empty")
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return r, errors.New("invalid recurrence part: " + part)
+		}
+		// Nama zona waktu peka huruf besar/kecil, bagian lain tidak
+		key = strings.ToUpper(key)
+		if key != "TZID" {
+			value = strings.ToUpper(value)
+		}
+		if seen[key] {
+			return r, errors.New("duplicate recurrence part: " + key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			if value != FreqDaily && value != FreqWeekly && value != FreqMonthly {
+				return r, errors.New("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			r.Freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxRecurrenceInterval {
+				return r, errors.New("INTERVAL must be a number between 1 and 365")
+			}
+			r.Interval = n
+		case "BYDAY":
+			days := map[time.Weekday]bool{}
+			for _, code := range strings.Split(value, ",") {
+				day, ok := rruleWeekdays[code]
+				if !ok {
+					return r, errors.New("invalid BYDAY value: " + code)
+				}
+				days[day] = true
+			}
+			for day := range days {
+				r.ByDay = append(r.ByDay, day)
+			}
+			sort.Slice(r.ByDay, func(i, j int) bool { return r.ByDay[i] < r.ByDay[j] })
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n == 0 || n < -1 || n > 31 {
+				return r, errors.New("BYMONTHDAY must be between 1 and 31, or -1 for the last day")
+			}
+			r.ByMonthDay = n
+		case "TZID":
+			loc, err := time.LoadLocation(value)
+			if err != nil || value == "Local" {
+				return r, errors.New("invalid TZID value: " + value)
+			}
+			r.Location = loc
+		default:
+			return r, errors.New("unsupported recurrence part: " + key)
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return r, errors.New("recurrence rule needs FREQ")
+	case len(r.ByDay) > 0 && r.Freq != FreqWeekly:
+		return r, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	case r.ByMonthDay != 0 && r.Freq != FreqMonthly:
+		return r, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return r, nil
+}
+
+// String mengembalikan aturan dalam bentuk RRULE yang baku (tanpa awalan "RRULE:").
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			for code, d := range rruleWeekdays {
+				if d == day {
+					codes[i] = code
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Location != nil {
+		parts = append(parts, "TZID="+r.Location.String())
+	}
+	return strings.Join(parts, ";")
+}
+
+// Anchor mengisi bagian yang bergantung pada tanggal awal, agar pengulangan tidak bergeser.
+// Tanpa ini, MONTHLY dari tanggal 31 akan menjadi tanggal 28 setelah melewati Februari.
+func (r Recurrence) Anchor(start time.Time) Recurrence {
+	if r.Location != nil {
+		start = start.In(r.Location)
+	}
+	if r.Freq == FreqMonthly && r.ByMonthDay == 0 {
+		r.ByMonthDay = start.Day()
+	}
+	return r
+}
+
+// Next menghitung kejadian pertama setelah from, dengan jam yang sama seperti from.
+// Hari dan tanggal dihitung di zona waktu TZID jika ada.
+// Untuk MONTHLY, tanggal yang tidak ada di bulan tersebut (mis. 31) jatuh ke hari terakhir bulan.
+func (r Recurrence) Next(from time.Time) time.Time {
+	if r.Location != nil {
+		from = from.In(r.Location)
+	}
+	switch r.Freq {
+	case FreqDaily:
+		return from.AddDate(0, 0, r.Interval)
+	case FreqWeekly:
+		return r.nextWeekly(from)
+	default:
+		return r.nextMonthly(from)
+	}
+}
+
+func (r Recurrence) nextWeekly(from time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return from.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// Minggu dihitung mulai Senin; hanya setiap minggu ke-Interval dari minggu from yang aktif
+	weekStart := from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	for i := 1; i <= 7*(r.Interval+1); i++ {
+		candidate := from.AddDate(0, 0, i)
+		week := daysBetween(weekStart, candidate) / 7
+		if week%r.Interval != 0 {
+			continue
+		}
+		for _, day := range r.ByDay {
+			if candidate.Weekday() == day {
+				return candidate
+			}
+		}
+	}
+	return from.AddDate(0, 0, 7*r.Interval)
+}
+
+func (r Recurrence) nextMonthly(from time.Time) time.Time {
+	day := r.ByMonthDay
+	if day == 0 {
+		day = from.Day()
+	}
+
+	// Dengan BYMONTHDAY, tanggal yang lebih akhir di bulan yang sama masih dihitung
+	if r.ByMonthDay != 0 {
+		if candidate := monthDay(from, 0, day); candidate.After(from) {
+			return candidate
+		}
+	}
+	return monthDay(from, r.Interval, day)
+}
+
+// monthDay mengembalikan tanggal day di bulan from+months, dibatasi ke hari terakhir bulan.
+func monthDay(from time.Time, months, day int) time.Time {
+	first := time.Date(from.Year(), from.Month()+time.Month(months), 1, from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day == -1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// daysBetween menghitung selisih hari kalender antara a dan b (b setelah a).
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
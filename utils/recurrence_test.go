@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string // Bentuk baku dari String(); kosong berarti harus error
+		wantErr bool
+	}{
+		{rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{rule: "rrule:freq=daily;interval=1", want: "FREQ=DAILY"},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH,MO,TH", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{rule: "FREQ=WEEKLY;BYDAY=MO;TZID=Asia/Jakarta", want: "FREQ=WEEKLY;BYDAY=MO;TZID=Asia/Jakarta"},
+		{rule: "", wantErr: true},
+		{rule: "INTERVAL=2", wantErr: true},
+		{rule: "FREQ=YEARLY", wantErr: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{rule: "FREQ=DAILY;INTERVAL=366", wantErr: true},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=3", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{rule: "FREQ=DAILY;TZID=Nowhere/City", wantErr: true},
+		{rule: "FREQ=DAILY;TZID=Local", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRecurrence(%q) = %q, want error", tt.rule, r.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q) error: %v", tt.rule, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseRecurrence(%q).String() = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time   // Tanggal awal untuk Anchor dan Next pertama
+		want  []time.Time // Kejadian berurutan hasil Next berulang kali
+	}{
+		{
+			name:  "daily interval",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: date(2026, time.October, 30, 9),
+			want:  []time.Time{date(2026, time.November, 2, 9), date(2026, time.November, 5, 9)},
+		},
+		{
+			name:  "weekly without BYDAY keeps the weekday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: date(2026, time.October, 7, 9),
+			want:  []time.Time{date(2026, time.October, 21, 9), date(2026, time.November, 4, 9)},
+		},
+		{
+			name:  "weekly interval with BYDAY skips the off week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: date(2026, time.October, 5, 9), // Senin
+			want: []time.Time{
+				date(2026, time.October, 8, 9),
+				date(2026, time.October, 19, 9),
+				date(2026, time.October, 22, 9),
+				date(2026, time.November, 2, 9),
+			},
+		},
+		{
+			name:  "weekly BYDAY from a day between the listed days",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR",
+			start: date(2026, time.October, 7, 9), // Rabu
+			want:  []time.Time{date(2026, time.October, 9, 9), date(2026, time.October, 12, 9)},
+		},
+		{
+			name:  "monthly anchored on the 31st in a leap year",
+			rule:  "FREQ=MONTHLY",
+			start: date(2024, time.January, 31, 9),
+			want: []time.Time{
+				date(2024, time.February, 29, 9),
+				date(2024, time.March, 31, 9),
+				date(2024, time.April, 30, 9),
+				date(2024, time.May, 31, 9),
+			},
+		},
+		{
+			name:  "monthly anchored on the 31st in a common year",
+			rule:  "FREQ=MONTHLY",
+			start: date(2025, time.January, 31, 9),
+			want:  []time.Time{date(2025, time.February, 28, 9), date(2025, time.March, 31, 9)},
+		},
+		{
+			name:  "monthly BYMONTHDAY later in the same month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=15",
+			start: date(2026, time.October, 10, 9),
+			want:  []time.Time{date(2026, time.October, 15, 9), date(2026, time.November, 15, 9)},
+		},
+		{
+			name:  "monthly last day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2026, time.January, 31, 9),
+			want:  []time.Time{date(2026, time.February, 28, 9), date(2026, time.March, 31, 9)},
+		},
+		{
+			// Senin 06:00 WIB adalah Minggu 23:00 UTC
+			name:  "weekly BYDAY counted in TZID",
+			rule:  "FREQ=WEEKLY;BYDAY=MO;TZID=Asia/Jakarta",
+			start: date(2026, time.October, 18, 23),
+			want:  []time.Time{time.Date(2026, time.October, 26, 6, 0, 0, 0, jakarta)},
+		},
+		{
+			// Tanggal 1 pukul 00:30 WIB masih tanggal 31 di UTC
+			name:  "monthly anchor counted in TZID",
+			rule:  "FREQ=MONTHLY;TZID=Asia/Jakarta",
+			start: time.Date(2026, time.March, 31, 17, 30, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, time.May, 1, 0, 30, 0, 0, jakarta),
+				time.Date(2026, time.June, 1, 0, 30, 0, 0, jakarta),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error: %v", tt.rule, err)
+			}
+			r = r.Anchor(tt.start)
+
+			from := tt.start
+			for i, want := range tt.want {
+				got := r.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d: Next(%s) = %s, want %s", i+1, from, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
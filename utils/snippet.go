@@ -0,0 +1,88 @@
+// utils/snippet.go
+package utils
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchTerms memecah kata kunci pencarian menjadi kata-kata (huruf kecil, tanpa duplikat)
+// yang dipakai untuk menyorot hasil.
+func SearchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		word = strings.ToLower(word)
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	// Kata yang lebih panjang dicocokkan lebih dulu agar sorotan tidak terpotong
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+// HighlightSnippet mengambil potongan teks di sekitar kata kunci pertama yang ditemukan
+// (radius karakter di kiri, dua kali radius di kanan), meng-escape HTML, lalu membungkus
+// setiap kata kunci dengan <mark>. Tanpa kecocokan, potongan diambil dari awal teks.
+func HighlightSnippet(text string, terms []string, radius int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matchAt := func(pos int) int {
+		for _, term := range terms {
+			t := []rune(term)
+			if pos+len(t) <= len(lower) && string(lower[pos:pos+len(t)]) == term {
+				return len(t)
+			}
+		}
+		return 0
+	}
+
+	first := -1
+	for i := range lower {
+		if matchAt(i) > 0 {
+			first = i
+			break
+		}
+	}
+
+	start, end := 0, len(runes)
+	if first > radius {
+		start = first - radius
+	}
+	if first < 0 {
+		first = 0
+	}
+	if limit := first + radius*2; limit < end {
+		end = limit
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		if n := matchAt(i); n > 0 {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i : i+n])))
+			b.WriteString("</mark>")
+			i += n
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
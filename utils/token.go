@@ -1,20 +1,122 @@
-// utils/token.go
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateToken(userID uint) (string, error) {
+const (
+	AccessTokenTTL  = time.Minute * 15    // Access token sengaja dibuat singkat
+	RefreshTokenTTL = time.Hour * 24 * 30 // Refresh token berlaku 30 hari sejak sesi dibuat
+	MFATokenTTL     = time.Minute * 5     // Batas waktu untuk memasukkan kode 2FA setelah password benar
+	InvitationTTL   = time.Hour * 24 * 7  // Undangan tim (beserta link-nya) berlaku 7 hari
+
+	TokenTypeAccess = "access"
+	TokenTypeMFA    = "mfa"
+	TokenTypeInvite = "invite"
+)
+
+// GenerateToken membuat access token JWT yang terikat ke sebuah sesi.
+// Token hanya dianggap valid selama sesinya belum dicabut.
+func GenerateToken(userID, sessionID uint) (string, error) {
 	claims := jwt.MapClaims{}
 	claims["authorized"] = true
+	claims["typ"] = TokenTypeAccess
 	claims["user_id"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix() // Token berlaku 24 jam
+	claims["session_id"] = sessionID
+	claims["exp"] = time.Now().Add(AccessTokenTTL).Unix()
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 }
+
+// GenerateMFAToken membuat token tantangan 2FA berumur pendek. Token ini hanya
+// membuktikan bahwa password sudah benar dan tidak bisa dipakai untuk mengakses API.
+// tokenID disimpan sebagai claim jti agar token hanya bisa ditukar sekali.
+func GenerateMFAToken(userID uint, deviceName, tokenID string) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["typ"] = TokenTypeMFA
+	claims["jti"] = tokenID
+	claims["user_id"] = userID
+	claims["device_name"] = deviceName
+	claims["exp"] = time.Now().Add(MFATokenTTL).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// GenerateInviteToken membuat token undangan bertanda tangan untuk orang yang belum punya akun.
+// Token mengikat ID undangan dengan alamat email yang diundang.
+func GenerateInviteToken(invitationID uint, email string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["typ"] = TokenTypeInvite
+	claims["invitation_id"] = invitationID
+	claims["email"] = email
+	claims["exp"] = expiresAt.Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// ParseToken memvalidasi tanda tangan & masa berlaku token lalu mengembalikan claims-nya.
+// Parameter tokenType memastikan token untuk satu keperluan tidak bisa dipakai untuk keperluan lain.
+func ParseToken(tokenString, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenType {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// ClaimUint membaca claim numerik (JSON number) sebagai uint.
+func ClaimUint(claims jwt.MapClaims, key string) (uint, bool) {
+	value, ok := claims[key].(float64)
+	if !ok || value <= 0 {
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// GenerateRefreshToken membuat refresh token acak yang tidak bisa ditebak.
+// Yang disimpan di database hanya hash-nya (lihat HashToken).
+func GenerateRefreshToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// GenerateTokenID membuat ID acak untuk claim jti.
+func GenerateTokenID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// HashToken menghasilkan hash SHA-256 dari sebuah token rahasia untuk disimpan di database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
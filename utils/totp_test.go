@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA-1 dari RFC 6238 Lampiran B ("12345678901234567890") dalam base32.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPRFC6238Vectors(t *testing.T) {
+	// Kode 8 digit dari RFC 6238 Lampiran B (SHA-1); yang dipakai 6 digit terakhirnya
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		step := tt.unix / totpPeriod
+		if got := hotp(key, step); got != tt.code {
+			t.Errorf("hotp(step %d) = %s, want %s", step, got, tt.code)
+		}
+
+		got, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0), 0)
+		if !ok || got != step {
+			t.Errorf("ValidateTOTP(%s at %d) = (%d, %v), want (%d, true)", tt.code, tt.unix, got, ok, step)
+		}
+	}
+}
+
+func TestValidateTOTPWindowAndReplay(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		step     int64 // Langkah waktu kode yang dikirim
+		lastStep int64
+		wantOK   bool
+	}{
+		{name: "current step", step: current, wantOK: true},
+		{name: "one step behind", step: current - 1, wantOK: true},
+		{name: "one step ahead", step: current + 1, wantOK: true},
+		{name: "two steps behind", step: current - 2, wantOK: false},
+		{name: "two steps ahead", step: current + 2, wantOK: false},
+		{name: "replay of the last used step", step: current, lastStep: current, wantOK: false},
+		{name: "step older than the last used step", step: current - 1, lastStep: current, wantOK: false},
+		{name: "step newer than the last used step", step: current + 1, lastStep: current, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		code := hotp(key, tt.step)
+		got, ok := ValidateTOTP(rfc6238Secret, code, now, tt.lastStep)
+		if ok != tt.wantOK {
+			t.Errorf("%s: ValidateTOTP ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if ok && got != tt.step {
+			t.Errorf("%s: ValidateTOTP step = %d, want %d", tt.name, got, tt.step)
+		}
+	}
+
+	if _, ok := ValidateTOTP(rfc6238Secret, "12345", now, 0); ok {
+		t.Error("ValidateTOTP accepted a 5-digit code")
+	}
+	if _, ok := ValidateTOTP("not base32!", hotp(key, current), now, 0); ok {
+		t.Error("ValidateTOTP accepted an invalid secret")
+	}
+}
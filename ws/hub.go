@@ -2,8 +2,11 @@
 package ws
 
 import (
+	"encoding/json"
 	"log"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,23 +17,68 @@ type Message struct {
 	Data  interface{} `json:"data"`
 }
 
+// EditingTTL adalah lama tanda "sedang mengedit" berlaku. Klien mengirim ulang
+// tanda tersebut sebelum kedaluwarsa selama user masih mengedit.
+const EditingTTL = 30 * time.Second
+
+// editingSweepInterval adalah jeda pemeriksaan tanda mengedit yang kedaluwarsa.
+const editingSweepInterval = 5 * time.Second
+
+// Editing adalah tanda bahwa seorang user sedang mengedit sebuah todo (soft lock).
+type Editing struct {
+	TeamID    uint      `json:"team_id"`
+	TodoID    uint      `json:"todo_id"`
+	UserID    uint      `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// editingKey mengidentifikasi tanda mengedit di dalam satu tim.
+type editingKey struct {
+	TodoID uint
+	UserID uint
+}
+
 // Client adalah representasi dari satu koneksi websocket
 type Client struct {
-	Conn   *websocket.Conn
-	Send   chan []byte
-	TeamID uint
+	Conn      *websocket.Conn
+	Send      chan []byte
+	UserID    uint // Pemilik koneksi
+	SessionID uint // Sesi login yang dipakai untuk membuka koneksi
+	Personal  bool // Koneksi /ws/me: menerima event pribadi user dan bisa mengikuti banyak tim
+
+	teams  map[uint]bool // Tim yang diikuti; hanya diakses oleh Hub dengan mu terkunci
+	closed bool          // true setelah channel Send ditutup
+}
+
+// NewClient membuat client untuk koneksi conn yang langsung mengikuti teamIDs.
+func NewClient(conn *websocket.Conn, userID, sessionID uint, personal bool, teamIDs ...uint) *Client {
+	client := &Client{
+		Conn:      conn,
+		Send:      make(chan []byte, 256),
+		UserID:    userID,
+		SessionID: sessionID,
+		Personal:  personal,
+		teams:     make(map[uint]bool, len(teamIDs)),
+	}
+	for _, teamID := range teamIDs {
+		client.teams[teamID] = true
+	}
+	return client
 }
 
 // Hub mengelola semua client dan broadcast pesan
 type Hub struct {
-	Clients    map[uint]map[*Client]bool // Peta dari TeamID ke peta Client
+	Clients    map[uint]map[*Client]bool         // Peta dari TeamID ke client yang mengikuti tim tersebut
+	users      map[uint]map[*Client]bool         // Peta dari UserID ke koneksi pribadi (Personal) milik user
+	presence   map[uint]map[uint]int             // Peta dari TeamID ke UserID dan jumlah koneksinya di tim itu
+	editing    map[uint]map[editingKey]time.Time // Peta dari TeamID ke tanda mengedit dan waktu kedaluwarsanya
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan struct { // Struct untuk membawa pesan dan TeamID
 		Message []byte
 		TeamID  uint
 	}
-	mu sync.Mutex // Untuk melindungi akses ke map Clients
+	mu sync.Mutex // Untuk melindungi akses ke semua map di Hub dan langganan client
 }
 
 // Global instance dari Hub
@@ -39,6 +87,9 @@ var AppHub = NewHub()
 func NewHub() *Hub {
 	return &Hub{
 		Clients:    make(map[uint]map[*Client]bool),
+		users:      make(map[uint]map[*Client]bool),
+		presence:   make(map[uint]map[uint]int),
+		editing:    make(map[uint]map[editingKey]time.Time),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Broadcast: make(chan struct {
@@ -49,45 +100,375 @@ func NewHub() *Hub {
 }
 
 func (h *Hub) Run() {
+	ticker := time.NewTicker(editingSweepInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case client := <-h.Register:
 			h.mu.Lock()
-			if _, ok := h.Clients[client.TeamID]; !ok {
-				h.Clients[client.TeamID] = make(map[*Client]bool)
+			for teamID := range client.teams {
+				h.addToTeam(client, teamID)
 			}
-			h.Clients[client.TeamID][client] = true
-			log.Printf("Client registered to team %d. Total clients in team: %d", client.TeamID, len(h.Clients[client.TeamID]))
+			if client.Personal {
+				if _, ok := h.users[client.UserID]; !ok {
+					h.users[client.UserID] = make(map[*Client]bool)
+				}
+				h.users[client.UserID][client] = true
+			}
+			log.Printf("Client of user %d registered to %d team(s)", client.UserID, len(client.teams))
 			h.mu.Unlock()
 
 		case client := <-h.Unregister:
 			h.mu.Lock()
-			if _, ok := h.Clients[client.TeamID]; ok {
-				if _, ok := h.Clients[client.TeamID][client]; ok {
-					delete(h.Clients[client.TeamID], client)
-					close(client.Send)
-					if len(h.Clients[client.TeamID]) == 0 {
-						delete(h.Clients, client.TeamID)
-					}
-					log.Printf("Client unregistered from team %d. Total clients in team: %d", client.TeamID, len(h.Clients[client.TeamID]))
-				}
+			if !client.closed {
+				h.removeClient(client)
+				log.Printf("Client of user %d unregistered", client.UserID)
 			}
 			h.mu.Unlock()
 
 		case broadcast := <-h.Broadcast:
 			h.mu.Lock()
-			if clients, ok := h.Clients[broadcast.TeamID]; ok {
-				for client := range clients {
-					select {
-					case client.Send <- broadcast.Message:
-					default:
-						// Gagal mengirim, mungkin koneksi terputus. Unregister client.
-						close(client.Send)
-						delete(clients, client)
-					}
-				}
+			for client := range h.Clients[broadcast.TeamID] {
+				h.send(client, broadcast.Message)
 			}
 			h.mu.Unlock()
+
+		case now := <-ticker.C:
+			h.mu.Lock()
+			h.expireEditing(now)
+			h.mu.Unlock()
+		}
+	}
+}
+
+// addToTeam memasukkan client ke daftar penerima event tim. Jika ini koneksi pertama
+// user di tim tersebut, anggota lain menerima event presence_joined. Panggil dengan mu terkunci.
+func (h *Hub) addToTeam(client *Client, teamID uint) {
+	client.teams[teamID] = true
+	if _, ok := h.Clients[teamID]; !ok {
+		h.Clients[teamID] = make(map[*Client]bool)
+	}
+	if h.Clients[teamID][client] {
+		return
+	}
+	h.Clients[teamID][client] = true
+
+	if _, ok := h.presence[teamID]; !ok {
+		h.presence[teamID] = make(map[uint]int)
+	}
+	h.presence[teamID][client.UserID]++
+	if h.presence[teamID][client.UserID] == 1 {
+		h.teamEvent(teamID, "presence_joined", map[string]uint{"team_id": teamID, "user_id": client.UserID})
+	}
+}
+
+// removeFromTeam mengeluarkan client dari daftar penerima event tim. Jika ini koneksi
+// terakhir user di tim tersebut, tanda mengedit miliknya dihapus dan anggota lain
+// menerima event presence_left. Panggil dengan mu terkunci.
+func (h *Hub) removeFromTeam(client *Client, teamID uint) {
+	delete(client.teams, teamID)
+	clients, ok := h.Clients[teamID]
+	if !ok || !clients[client] {
+		return
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.Clients, teamID)
+	}
+
+	h.presence[teamID][client.UserID]--
+	if h.presence[teamID][client.UserID] > 0 {
+		return
+	}
+	delete(h.presence[teamID], client.UserID)
+	if len(h.presence[teamID]) == 0 {
+		delete(h.presence, teamID)
+	}
+	for key := range h.editing[teamID] {
+		if key.UserID == client.UserID {
+			h.stopEditing(teamID, key)
+		}
+	}
+	h.teamEvent(teamID, "presence_left", map[string]uint{"team_id": teamID, "user_id": client.UserID})
+}
+
+// teamEvent mengirim event ke semua client yang mengikuti tim. Panggil dengan mu terkunci.
+func (h *Hub) teamEvent(teamID uint, event string, data interface{}) {
+	jsonMsg, err := json.Marshal(Message{Event: event, Data: data})
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v", event, err)
+		return
+	}
+	for client := range h.Clients[teamID] {
+		h.send(client, jsonMsg)
+	}
+}
+
+// stopEditing menghapus satu tanda mengedit lalu mengirim event editing_stopped.
+// Panggil dengan mu terkunci.
+func (h *Hub) stopEditing(teamID uint, key editingKey) {
+	if _, ok := h.editing[teamID][key]; !ok {
+		return
+	}
+	delete(h.editing[teamID], key)
+	if len(h.editing[teamID]) == 0 {
+		delete(h.editing, teamID)
+	}
+	h.teamEvent(teamID, "editing_stopped", map[string]uint{"team_id": teamID, "todo_id": key.TodoID, "user_id": key.UserID})
+}
+
+// expireEditing menghapus tanda mengedit yang sudah lewat EditingTTL tanpa diperbarui.
+// Panggil dengan mu terkunci.
+func (h *Hub) expireEditing(now time.Time) {
+	for teamID, marks := range h.editing {
+		for key, expiresAt := range marks {
+			if !now.Before(expiresAt) {
+				h.stopEditing(teamID, key)
+			}
+		}
+	}
+}
+
+// removeClient melepas client dari semua tim dan menutup channel Send-nya.
+// Menutup channel Send membuat writePump mengirim close frame dan menutup koneksi.
+// Panggil dengan mu terkunci.
+func (h *Hub) removeClient(client *Client) {
+	if client.closed {
+		return
+	}
+	client.closed = true
+	close(client.Send)
+
+	for teamID := range client.teams {
+		h.removeFromTeam(client, teamID)
+	}
+	if clients, ok := h.users[client.UserID]; ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.users, client.UserID)
+		}
+	}
+}
+
+// send mengirim pesan ke satu client tanpa menunggu. Panggil dengan mu terkunci.
+func (h *Hub) send(client *Client, message []byte) {
+	if client.closed {
+		return
+	}
+	select {
+	case client.Send <- message:
+	default:
+		// Gagal mengirim, mungkin koneksi terputus. Unregister client.
+		h.removeClient(client)
+	}
+}
+
+// sendEvent membungkus event & data lalu mengirimkannya ke satu client. Panggil dengan mu terkunci.
+func (h *Hub) sendEvent(client *Client, event string, data interface{}) {
+	jsonMsg, err := json.Marshal(Message{Event: event, Data: data})
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v", event, err)
+		return
+	}
+	h.send(client, jsonMsg)
+}
+
+// disconnectWhere menutup semua client yang cocok dengan kriteria match.
+func (h *Hub) disconnectWhere(match func(client *Client) bool) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Koneksi pribadi ada di Clients dan users sekaligus; hitung sekali saja
+	closed := 0
+	for _, clients := range h.Clients {
+		for client := range clients {
+			if !client.closed && match(client) {
+				h.removeClient(client)
+				closed++
+			}
+		}
+	}
+	for _, clients := range h.users {
+		for client := range clients {
+			if !client.closed && match(client) {
+				h.removeClient(client)
+				closed++
+			}
+		}
+	}
+	return closed
+}
+
+// DisconnectSession memutus semua koneksi websocket yang dibuka dengan sesi tertentu.
+func (h *Hub) DisconnectSession(sessionID uint) {
+	if n := h.disconnectWhere(func(client *Client) bool { return client.SessionID == sessionID }); n > 0 {
+		log.Printf("Disconnected %d client(s) of revoked session %d", n, sessionID)
+	}
+}
+
+// BroadcastToTeam membungkus event & data menjadi Message lalu mengirimkannya
+// ke semua client yang terhubung ke tim tersebut. Pesan langsung masuk antrean client
+// sebelum fungsi kembali, jadi event ini pasti terkirim sebelum client dilepas dari tim.
+func (h *Hub) BroadcastToTeam(teamID uint, event string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.teamEvent(teamID, event, data)
+}
+
+// Presence mengembalikan ID user yang sedang terhubung ke tim (minimal satu koneksi)
+// serta tanda mengedit yang masih berlaku, keduanya terurut.
+func (h *Hub) Presence(teamID uint) ([]uint, []Editing) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	userIDs := make([]uint, 0, len(h.presence[teamID]))
+	for userID := range h.presence[teamID] {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	editing := make([]Editing, 0, len(h.editing[teamID]))
+	for key, expiresAt := range h.editing[teamID] {
+		editing = append(editing, Editing{TeamID: teamID, TodoID: key.TodoID, UserID: key.UserID, ExpiresAt: expiresAt})
+	}
+	sort.Slice(editing, func(i, j int) bool {
+		if editing[i].TodoID != editing[j].TodoID {
+			return editing[i].TodoID < editing[j].TodoID
+		}
+		return editing[i].UserID < editing[j].UserID
+	})
+	return userIDs, editing
+}
+
+// StartEditing menandai pemilik client sedang mengedit todo selama EditingTTL. Tanda baru
+// diumumkan ke tim lewat event editing_started; tanda yang sudah ada hanya diperpanjang.
+// Mengembalikan false jika client tidak mengikuti tim tersebut.
+func (h *Hub) StartEditing(client *Client, teamID, todoID uint) (Editing, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if client.closed || !client.teams[teamID] {
+		return Editing{}, false
+	}
+	if _, ok := h.editing[teamID]; !ok {
+		h.editing[teamID] = make(map[editingKey]time.Time)
+	}
+	key := editingKey{TodoID: todoID, UserID: client.UserID}
+	_, exists := h.editing[teamID][key]
+	editing := Editing{TeamID: teamID, TodoID: todoID, UserID: client.UserID, ExpiresAt: time.Now().Add(EditingTTL)}
+	h.editing[teamID][key] = editing.ExpiresAt
+	if !exists {
+		h.teamEvent(teamID, "editing_started", editing)
+	}
+	return editing, true
+}
+
+// StopEditing menghapus tanda mengedit user pada todo sebelum kedaluwarsa.
+func (h *Hub) StopEditing(teamID, todoID, userID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopEditing(teamID, editingKey{TodoID: todoID, UserID: userID})
+}
+
+// SendToUser mengirim event ke semua koneksi pribadi (Personal) milik user.
+func (h *Hub) SendToUser(userID uint, event string, data interface{}) {
+	jsonMsg, err := json.Marshal(Message{Event: event, Data: data})
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v", event, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.send(client, jsonMsg)
+	}
+}
+
+// SendToClient mengirim event hanya ke satu client, mis. balasan untuk pesan dari client tersebut.
+func (h *Hub) SendToClient(client *Client, event string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sendEvent(client, event, data)
+}
+
+// Subscribe membuat client ikut menerima event tim. Pemanggil harus sudah memastikan
+// pemilik client adalah anggota tim tersebut.
+func (h *Hub) Subscribe(client *Client, teamID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !client.closed {
+		h.addToTeam(client, teamID)
+	}
+}
+
+// Unsubscribe menghentikan event tim untuk client.
+func (h *Hub) Unsubscribe(client *Client, teamID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeFromTeam(client, teamID)
+}
+
+// Teams mengembalikan ID tim yang sedang diikuti client.
+func (h *Hub) Teams(client *Client) []uint {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	teamIDs := make([]uint, 0, len(client.teams))
+	for teamID := range client.teams {
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs
+}
+
+// SubscribeUser membuat semua koneksi pribadi user mengikuti tim yang baru ia masuki,
+// lalu memberi tahu klien lewat event "subscribed".
+func (h *Hub) SubscribeUser(userID, teamID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.addToTeam(client, teamID)
+		h.sendEvent(client, "subscribed", map[string]uint{"team_id": teamID})
+	}
+}
+
+// DisconnectUserFromTeam menghentikan event tim untuk semua koneksi milik user,
+// misalnya setelah user dikeluarkan dari tim. Koneksi per tim diputus, sedangkan
+// koneksi pribadi hanya berhenti mengikuti tim tersebut (event "unsubscribed").
+func (h *Hub) DisconnectUserFromTeam(userID, teamID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	closed := 0
+	for client := range h.Clients[teamID] {
+		if client.UserID != userID {
+			continue
+		}
+		if client.Personal {
+			h.removeFromTeam(client, teamID)
+			h.sendEvent(client, "unsubscribed", map[string]uint{"team_id": teamID})
+			continue
+		}
+		h.removeClient(client)
+		closed++
+	}
+	if closed > 0 {
+		log.Printf("Disconnected %d client(s) of user %d from team %d", closed, userID, teamID)
+	}
+}
+
+// UnsubscribeTeam menghentikan event tim untuk semua koneksi, misalnya setelah tim
+// dipindahkan ke tempat sampah. Aturannya sama dengan DisconnectUserFromTeam.
+func (h *Hub) UnsubscribeTeam(teamID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.Clients[teamID] {
+		if client.Personal {
+			h.removeFromTeam(client, teamID)
+			h.sendEvent(client, "unsubscribed", map[string]uint{"team_id": teamID})
+			continue
 		}
+		h.removeClient(client)
 	}
 }